@@ -27,13 +27,14 @@ var _ = math.Inf
 type Envelope_EventType int32
 
 const (
-	Envelope_Heartbeat     Envelope_EventType = 1
-	Envelope_HttpStart     Envelope_EventType = 2
-	Envelope_HttpStop      Envelope_EventType = 3
-	Envelope_HttpStartStop Envelope_EventType = 4
-	Envelope_LogMessage    Envelope_EventType = 5
-	Envelope_ValueMetric   Envelope_EventType = 6
-	Envelope_CounterEvent  Envelope_EventType = 7
+	Envelope_Heartbeat       Envelope_EventType = 1
+	Envelope_HttpStart       Envelope_EventType = 2
+	Envelope_HttpStop        Envelope_EventType = 3
+	Envelope_HttpStartStop   Envelope_EventType = 4
+	Envelope_LogMessage      Envelope_EventType = 5
+	Envelope_ValueMetric     Envelope_EventType = 6
+	Envelope_CounterEvent    Envelope_EventType = 7
+	Envelope_ContainerMetric Envelope_EventType = 8
 )
 
 var Envelope_EventType_name = map[int32]string{
@@ -44,15 +45,17 @@ var Envelope_EventType_name = map[int32]string{
 	5: "LogMessage",
 	6: "ValueMetric",
 	7: "CounterEvent",
+	8: "ContainerMetric",
 }
 var Envelope_EventType_value = map[string]int32{
-	"Heartbeat":     1,
-	"HttpStart":     2,
-	"HttpStop":      3,
-	"HttpStartStop": 4,
-	"LogMessage":    5,
-	"ValueMetric":   6,
-	"CounterEvent":  7,
+	"Heartbeat":       1,
+	"HttpStart":       2,
+	"HttpStop":        3,
+	"HttpStartStop":   4,
+	"LogMessage":      5,
+	"ValueMetric":     6,
+	"CounterEvent":    7,
+	"ContainerMetric": 8,
 }
 
 func (x Envelope_EventType) Enum() *Envelope_EventType {
@@ -83,6 +86,7 @@ type Envelope struct {
 	LogMessage       *LogMessage         `protobuf:"bytes,8,opt,name=logMessage" json:"logMessage,omitempty"`
 	ValueMetric      *ValueMetric        `protobuf:"bytes,9,opt,name=valueMetric" json:"valueMetric,omitempty"`
 	CounterEvent     *CounterEvent       `protobuf:"bytes,10,opt,name=counterEvent" json:"counterEvent,omitempty"`
+	ContainerMetric  *ContainerMetric    `protobuf:"bytes,11,opt,name=containerMetric" json:"containerMetric,omitempty"`
 	XXX_unrecognized []byte              `json:"-"`
 }
 
@@ -160,6 +164,13 @@ func (m *Envelope) GetCounterEvent() *CounterEvent {
 	return nil
 }
 
+func (m *Envelope) GetContainerMetric() *ContainerMetric {
+	if m != nil {
+		return m.ContainerMetric
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterEnum("events.Envelope_EventType", Envelope_EventType_name, Envelope_EventType_value)
 }
@@ -8,6 +8,7 @@ import (
 
 	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
 	. "github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gbytes"
@@ -145,16 +146,9 @@ EOF
 	Context("when the buildpack is disabled", func() {
 		BeforeEach(func() {
 			AsUser(context.AdminUserContext(), func() {
-				var response QueryResponse
+				buildpackGuid := ccapi.FindBuildpack(BuildpackName).Metadata.Guid
 
-				ApiRequest("GET", "/v2/buildpacks?q=name:"+BuildpackName, &response)
-
-				Expect(response.Resources).To(HaveLen(1))
-
-				buildpackGuid := response.Resources[0].Metadata.Guid
-
-				ApiRequest(
-					"PUT",
+				ccapi.Put(
 					"/v2/buildpacks/"+buildpackGuid,
 					nil,
 					`{"enabled":false}`,
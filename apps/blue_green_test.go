@@ -0,0 +1,60 @@
+package apps
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/appclient"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/matchers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/push"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/routing"
+)
+
+var _ = Describe("Blue/green deployment", func() {
+	var host string
+	var blueName, greenName string
+	var load *appclient.Load
+
+	pushColor := func(appName, hostname, color string) {
+		options := push.Options{Hostname: hostname, Env: map[string]string{"COLOR": color}}
+		ExpectWithOffset(1, push.Push(appName, assets.NewAssets().Dora, options).Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
+	}
+
+	BeforeEach(func() {
+		host = generator.RandomName()
+		blueName = generator.RandomName()
+		greenName = generator.RandomName()
+
+		pushColor(blueName, host, "blue")
+		pushColor(greenName, greenName, "green")
+
+		Eventually(appClient.Probing(host, "/env/COLOR"), CF_PUSH_TIMEOUT).Should(BeReachableWithBody(ContainSubstring("blue")))
+		Eventually(appClient.Probing(greenName, "/env/COLOR"), CF_PUSH_TIMEOUT).Should(BeReachableWithBody(ContainSubstring("green")))
+	})
+
+	AfterEach(func() {
+		if load != nil {
+			load.Stop()
+			load = nil
+		}
+	})
+
+	It("switches the route to the new app without failing a request", func() {
+		load = appClient.StartLoad(host, "/env/COLOR", 50*time.Millisecond)
+
+		mapping := time.Now()
+		routing.MapRoute(greenName, appClient.AppsDomain, host, DEFAULT_TIMEOUT)
+		Eventually(func() []string { return load.Bodies(10) }, DEFAULT_TIMEOUT).Should(ContainElement("green"), "green never served the route")
+
+		routing.UnmapRoute(blueName, appClient.AppsDomain, host, DEFAULT_TIMEOUT)
+		unmapped := time.Now()
+		Eventually(func() []string { return load.Bodies(10) }, DEFAULT_TIMEOUT).ShouldNot(ContainElement("blue"), "blue kept serving the route")
+
+		Expect(load.FailuresBetween(mapping, unmapped)).To(BeEmpty(), "requests failed while the route moved")
+	})
+})
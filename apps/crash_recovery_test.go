@@ -0,0 +1,84 @@
+package apps
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/push"
+)
+
+var _ = Describe("Crash recovery", func() {
+	var appName string
+	var appGuid string
+
+	running := func(instance ccapi.AppInstance) bool {
+		return instance.State == "RUNNING"
+	}
+
+	runningSince := func(previous ccapi.AppInstance) func(ccapi.AppInstance) bool {
+		return func(instance ccapi.AppInstance) bool {
+			return running(instance) && instance.Since > previous.Since
+		}
+	}
+
+	waitForInstance := func(index int, ready func(ccapi.AppInstance) bool) ccapi.AppInstance {
+		instance, err := ccapi.WaitForInstance(appGuid, index, CF_PUSH_TIMEOUT, ready)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return instance
+	}
+
+	crashesOf := func(index int) func() []ccapi.CrashEvent {
+		return func() []ccapi.CrashEvent {
+			crashes := []ccapi.CrashEvent{}
+			for _, event := range ccapi.AppCrashEvents(appGuid) {
+				if event.Entity.Metadata.Index == index {
+					crashes = append(crashes, event)
+				}
+			}
+			return crashes
+		}
+	}
+
+	BeforeEach(func() {
+		appName = generator.RandomName()
+		Expect(push.Push(appName, assets.NewAssets().Dora, push.Options{Instances: 2}).Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
+		appGuid = ccapi.FindApp(appName).Metadata.Guid
+
+		waitForInstance(0, running)
+		waitForInstance(1, running)
+	})
+
+	It("restarts a killed instance and records why it crashed", func() {
+		before := ccapi.GetAppInstances(appGuid)
+
+		appClient.KillInstance(appName, 1)
+
+		waitForInstance(1, runningSince(before["1"]))
+		Expect(ccapi.GetAppInstances(appGuid)["0"].Since).To(Equal(before["0"].Since), "the other instance should not have restarted")
+
+		Eventually(crashesOf(1), DEFAULT_TIMEOUT).Should(HaveLen(1))
+		crash := crashesOf(1)()[0]
+		Expect(crash.Entity.Actee).To(Equal(appGuid))
+		Expect(crash.Entity.Metadata.Reason).To(Equal("CRASHED"))
+		Expect(crash.Entity.Metadata.ExitStatus).NotTo(BeZero())
+		Expect(crash.Entity.Metadata.ExitDescription).NotTo(BeEmpty())
+		Expect(crash.Entity.Metadata.Instance).NotTo(BeEmpty())
+
+		Expect(crashesOf(0)()).To(BeEmpty())
+	})
+
+	It("counts every restart of an instance", func() {
+		for restarts := 1; restarts <= 2; restarts++ {
+			before := ccapi.GetAppInstances(appGuid)
+
+			appClient.KillInstance(appName, 0)
+
+			waitForInstance(0, runningSince(before["0"]))
+			Eventually(crashesOf(0), DEFAULT_TIMEOUT).Should(HaveLen(restarts))
+		}
+	})
+})
@@ -8,7 +8,6 @@ import (
 
 	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
 	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
-	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
 )
 
@@ -41,7 +40,7 @@ var _ = Describe("A running application", func() {
 	})
 
 	It("can show crash events", func() {
-		helpers.CurlApp(appName, "/sigterm/KILL")
+		appClient.Body(appName, "/sigterm/KILL")
 
 		Eventually(func() string {
 			return string(cf.Cf("events", appName).Wait(DEFAULT_TIMEOUT).Out.Contents())
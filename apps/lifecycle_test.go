@@ -10,43 +10,29 @@ import (
 	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
 	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/matchers"
 )
 
-type AppUsageEvent struct {
-	Entity struct {
-		AppName       string `json:"app_name"`
-		State         string `json:"state"`
-		BuildpackName string `json:"buildpack_name"`
-		BuildpackGuid string `json:"buildpack_guid"`
-	} `json:"entity"`
-}
-
-type AppUsageEvents struct {
-	Resources []AppUsageEvent `struct:"resources"`
-}
-
-func lastAppUsageEvent(appName string, state string) (bool, AppUsageEvent) {
-	var response AppUsageEvents
+func lastAppUsageEvent(app ccapi.App, state string) (bool, ccapi.AppUsageEvent) {
+	var event ccapi.AppUsageEvent
+	var found bool
 	cf.AsUser(context.AdminUserContext(), func() {
-		cf.ApiRequest("GET", "/v2/app_usage_events?order-direction=desc&page=1", &response)
+		event, found = ccapi.LastAppUsageEvent(app, state)
 	})
 
-	for _, event := range response.Resources {
-		if event.Entity.AppName == appName && event.Entity.State == state {
-			return true, event
-		}
-	}
-
-	return false, AppUsageEvent{}
+	return found, event
 }
 
 var _ = Describe("Application Lifecycle", func() {
 	var appName string
+	var app ccapi.App
 
 	BeforeEach(func() {
 		appName = generator.RandomName()
 
 		Expect(cf.Cf("push", appName, "-p", assets.NewAssets().Dora).Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
+		app = ccapi.FindApp(appName)
 	})
 
 	AfterEach(func() {
@@ -55,9 +41,7 @@ var _ = Describe("Application Lifecycle", func() {
 
 	Describe("pushing", func() {
 		It("makes the app reachable via its bound route", func() {
-			Eventually(func() string {
-				return helpers.CurlAppRoot(appName)
-			}, DEFAULT_TIMEOUT).Should(ContainSubstring("Hi, I'm Dora!"))
+			Eventually(appClient.Probing(appName, "/"), DEFAULT_TIMEOUT).Should(BeReachableWithBody(ContainSubstring("Hi, I'm Dora!")))
 		})
 
 		It("makes system environment variables available", func() {
@@ -73,12 +57,12 @@ var _ = Describe("Application Lifecycle", func() {
 		})
 
 		It("generates an app usage 'started' event", func() {
-			found, _ := lastAppUsageEvent(appName, "STARTED")
+			found, _ := lastAppUsageEvent(app, "STARTED")
 			Expect(found).To(BeTrue())
 		})
 
 		It("generates an app usage 'buildpack_set' event", func() {
-			found, matchingEvent := lastAppUsageEvent(appName, "BUILDPACK_SET")
+			found, matchingEvent := lastAppUsageEvent(app, "BUILDPACK_SET")
 
 			Expect(found).To(BeTrue())
 			Expect(matchingEvent.Entity.BuildpackName).To(Equal("ruby_buildpack"))
@@ -92,13 +76,11 @@ var _ = Describe("Application Lifecycle", func() {
 		})
 
 		It("makes the app unreachable", func() {
-			Eventually(func() string {
-				return helpers.CurlAppRoot(appName)
-			}, DEFAULT_TIMEOUT).Should(ContainSubstring("404"))
+			Eventually(appClient.Probing(appName, "/"), DEFAULT_TIMEOUT).Should(BeUnroutable())
 		})
 
 		It("generates an app usage 'stopped' event", func() {
-			found, _ := lastAppUsageEvent(appName, "STOPPED")
+			found, _ := lastAppUsageEvent(app, "STOPPED")
 			Expect(found).To(BeTrue())
 		})
 
@@ -108,24 +90,18 @@ var _ = Describe("Application Lifecycle", func() {
 			})
 
 			It("makes the app reachable again", func() {
-				Eventually(func() string {
-					return helpers.CurlAppRoot(appName)
-				}, DEFAULT_TIMEOUT).Should(ContainSubstring("Hi, I'm Dora!"))
+				Eventually(appClient.Probing(appName, "/"), DEFAULT_TIMEOUT).Should(BeReachableWithBody(ContainSubstring("Hi, I'm Dora!")))
 			})
 		})
 	})
 
 	Describe("updating", func() {
 		It("is reflected through another push", func() {
-			Eventually(func() string {
-				return helpers.CurlAppRoot(appName)
-			}, DEFAULT_TIMEOUT).Should(ContainSubstring("Hi, I'm Dora!"))
+			Eventually(appClient.Probing(appName, "/"), DEFAULT_TIMEOUT).Should(BeReachableWithBody(ContainSubstring("Hi, I'm Dora!")))
 
 			Expect(cf.Cf("push", appName, "-p", assets.NewAssets().HelloWorld).Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
 
-			Eventually(func() string {
-				return helpers.CurlAppRoot(appName)
-			}, DEFAULT_TIMEOUT).Should(ContainSubstring("Hello, world!"))
+			Eventually(appClient.Probing(appName, "/"), DEFAULT_TIMEOUT).Should(BeReachableWithBody(ContainSubstring("Hello, world!")))
 		})
 	})
 
@@ -141,13 +117,11 @@ var _ = Describe("Application Lifecycle", func() {
 		})
 
 		It("makes the app unreachable", func() {
-			Eventually(func() string {
-				return helpers.CurlAppRoot(appName)
-			}, DEFAULT_TIMEOUT).Should(ContainSubstring("404"))
+			Eventually(appClient.Probing(appName, "/"), DEFAULT_TIMEOUT).Should(BeUnroutable())
 		})
 
 		It("generates an app usage 'stopped' event", func() {
-			found, _ := lastAppUsageEvent(appName, "STOPPED")
+			found, _ := lastAppUsageEvent(app, "STOPPED")
 			Expect(found).To(BeTrue())
 		})
 	})
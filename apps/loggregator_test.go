@@ -13,11 +13,14 @@ import (
 	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
 	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
 	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/matchers"
 	"github.com/cloudfoundry/noaa"
+	"github.com/cloudfoundry/noaa/events"
 
 	"crypto/tls"
 	"strings"
+	"sync"
 
 	"encoding/json"
 	"os"
@@ -78,22 +81,97 @@ var _ = Describe("loggregator", func() {
 	})
 
 	Context("firehose data", func() {
-		It("shows logs and metrics", func() {
+		var noaaConnection noaa.Noaa
+		var envelopes *envelopeCollector
+		var appGuid string
+
+		BeforeEach(func() {
 			config := helpers.LoadConfig()
+			appGuid = ccapi.FindApp(appName).Metadata.Guid
 
-			noaaConnection := noaa.NewNoaa(getDopplerEndpoint(), &tls.Config{InsecureSkipVerify: config.SkipSSLValidation}, nil)
+			noaaConnection = noaa.NewNoaa(getDopplerEndpoint(), &tls.Config{InsecureSkipVerify: config.SkipSSLValidation}, nil)
 			msgChan, err := noaaConnection.Firehose("firehose-a", getAdminUserAccessToken())
 			Expect(err).NotTo(HaveOccurred())
 
+			envelopes = collectEnvelopes(msgChan, appGuid)
+		})
+
+		AfterEach(func() {
+			noaaConnection.Close()
+		})
+
+		It("shows logs and metrics", func() {
 			Eventually(func() string {
 				return helpers.CurlApp(appName, fmt.Sprintf("/log/sleep/%d", oneSecond))
 			}, DEFAULT_TIMEOUT).Should(ContainSubstring("Muahaha"))
 
-			Eventually(msgChan, DEFAULT_TIMEOUT).Should(Receive(EnvelopeContainingMessageLike("Muahaha")), "To enable the logging & metrics firehose feature, please ask your CF administrator to add the 'doppler.firehose' scope to your CF admin user.")
+			Eventually(envelopes.Received, DEFAULT_TIMEOUT).Should(ContainElement(EnvelopeContainingMessageLike("Muahaha")), "To enable the logging & metrics firehose feature, please ask your CF administrator to add the 'doppler.firehose' scope to your CF admin user.")
+
+			Eventually(envelopes.Received, DEFAULT_TIMEOUT).Should(ContainElement(
+				EnvelopeOfType(events.Envelope_LogMessage).ForApp(appGuid).WithSourceType("App").WithLogMessageMatching("Muahaha"),
+			))
+			Eventually(envelopes.Received, DEFAULT_TIMEOUT).Should(ContainElement(
+				EnvelopeOfType(events.Envelope_HttpStartStop).ForApp(appGuid).WithStatusCode(200),
+			))
+			Eventually(envelopes.Received, DEFAULT_TIMEOUT).Should(ContainElement(EnvelopeOfType(events.Envelope_ValueMetric)))
+			Eventually(envelopes.Received, DEFAULT_TIMEOUT).Should(ContainElement(EnvelopeOfType(events.Envelope_CounterEvent)))
+		})
+
+		It("shows the app's container metrics", func() {
+			Eventually(envelopes.Received, DEFAULT_TIMEOUT).Should(ContainElement(
+				EnvelopeOfType(events.Envelope_ContainerMetric).ForApp(appGuid).WithContainerMetric(0),
+			))
 		})
 	})
 })
 
+// metricsKept is how many envelopes of each metric type an envelopeCollector
+// keeps.
+const metricsKept = 10
+
+type envelopeCollector struct {
+	sync.Mutex
+	appGuid   string
+	envelopes []*events.Envelope
+	metrics   map[events.Envelope_EventType]int
+}
+
+// collectEnvelopes records the firehose envelopes about the app and the first
+// few value metrics and counter events, which belong to no app. The firehose
+// carries the traffic of the whole platform, so everything else is dropped.
+func collectEnvelopes(msgChan <-chan *events.Envelope, appGuid string) *envelopeCollector {
+	collector := &envelopeCollector{
+		appGuid: appGuid,
+		metrics: map[events.Envelope_EventType]int{},
+	}
+	go func() {
+		for envelope := range msgChan {
+			collector.Lock()
+			if collector.keep(envelope) {
+				collector.envelopes = append(collector.envelopes, envelope)
+			}
+			collector.Unlock()
+		}
+	}()
+	return collector
+}
+
+func (c *envelopeCollector) keep(envelope *events.Envelope) bool {
+	switch eventType := envelope.GetEventType(); eventType {
+	case events.Envelope_ValueMetric, events.Envelope_CounterEvent:
+		c.metrics[eventType]++
+		return c.metrics[eventType] <= metricsKept
+	}
+	return EnvelopeAppId(envelope) == c.appGuid
+}
+
+func (c *envelopeCollector) Received() []*events.Envelope {
+	c.Lock()
+	defer c.Unlock()
+
+	return append([]*events.Envelope{}, c.envelopes...)
+}
+
 type cfHomeConfig struct {
 	AccessToken         string
 	LoggregatorEndpoint string
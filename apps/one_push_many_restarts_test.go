@@ -21,14 +21,16 @@ import (
 	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
 	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	catsconfig "github.com/cloudfoundry/cf-acceptance-tests/helpers/config"
 )
 
-var _ = Describe("An application that's already been pushed", func() {
+var _ = Describe("An application that's already been pushed "+catsconfig.Tag("include_persistent_app"), func() {
 	var appName string
-	config := helpers.LoadConfig()
+	var config helpers.Config
 	var environment *helpers.Environment
 
 	BeforeEach(func() {
+		config = helpers.LoadConfig()
 		persistentContext := helpers.NewPersistentAppContext(config)
 		environment = helpers.NewEnvironment(persistentContext)
 		environment.Setup()
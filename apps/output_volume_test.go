@@ -1,6 +1,7 @@
 package apps
 
 import (
+	"net/http"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -9,7 +10,7 @@ import (
 
 	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
 	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
-	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/appclient"
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
 )
 
@@ -27,16 +28,18 @@ var _ = Describe("An application printing a bunch of output", func() {
 	})
 
 	It("doesn't die when printing 32MB", func() {
-		beforeId := helpers.CurlApp(appName, "/id")
+		beforeId := appClient.Body(appName, "/id")
 
-		Expect(helpers.CurlAppWithTimeout(appName, "/logspew/32000", LONG_CURL_TIMEOUT)).
-			To(ContainSubstring("Just wrote 32000 kbytes to the log"))
+		logspew, err := appClient.Do(appName, appclient.Request{Path: "/logspew/32000", Timeout: LONG_CURL_TIMEOUT})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(logspew.StatusCode).To(Equal(http.StatusOK))
+		Expect(logspew.Body).To(ContainSubstring("Just wrote 32000 kbytes to the log"))
 
 		// Give time for components (i.e. Warden) to react to the output
 		// and potentially make bad decisions (like killing the app)
 		time.Sleep(10 * time.Second)
 
-		afterId := helpers.CurlApp(appName, "/id")
+		afterId := appClient.Body(appName, "/id")
 
 		Expect(beforeId).To(Equal(afterId))
 	})
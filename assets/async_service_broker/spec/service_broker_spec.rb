@@ -33,4 +33,86 @@ describe ServiceBroker do
       expect(JSON.parse(last_response.body)).to be_empty
     end
   end
+
+  describe "GET /v2/service_instances/:id/last_operation" do
+    after { CONFIG_DATA.delete('last_operations') }
+
+    it 'reports progress and then success by default' do
+      put '/v2/service_instances/fakeIDThough'
+      expect(last_response.status).to eq(202)
+
+      get '/v2/service_instances/fakeIDThough/last_operation'
+      expect(JSON.parse(last_response.body)['state']).to eq('in progress')
+
+      get '/v2/service_instances/fakeIDThough/last_operation'
+      expect(JSON.parse(last_response.body)).to eq('state' => 'succeeded', 'description' => '100% done')
+    end
+
+    it 'ends with the configured state' do
+      CONFIG_DATA['last_operations'] = { 'provision' => { 'state' => 'failed', 'description' => 'out of disks' } }
+
+      put '/v2/service_instances/failing'
+      get '/v2/service_instances/failing/last_operation'
+      get '/v2/service_instances/failing/last_operation'
+      expect(JSON.parse(last_response.body)).to eq('state' => 'failed', 'description' => 'out of disks')
+    end
+
+    it 'stays in progress when configured to' do
+      CONFIG_DATA['last_operations'] = { 'update' => { 'state' => 'in progress', 'description' => 'still updating' } }
+
+      patch '/v2/service_instances/slow'
+      3.times { get '/v2/service_instances/slow/last_operation' }
+      expect(JSON.parse(last_response.body)).to eq('state' => 'in progress', 'description' => 'still updating')
+    end
+
+    it 'answers 410 Gone while deprovisioning when configured to' do
+      CONFIG_DATA['last_operations'] = { 'deprovision' => { 'status' => 410 } }
+
+      put '/v2/service_instances/gone'
+      2.times { get '/v2/service_instances/gone/last_operation' }
+
+      delete '/v2/service_instances/gone'
+      expect(last_response.status).to eq(202)
+
+      get '/v2/service_instances/gone/last_operation'
+      expect(last_response.status).to eq(200)
+      get '/v2/service_instances/gone/last_operation'
+      expect(last_response.status).to eq(410)
+    end
+  end
+
+  describe "PUT /v2/service_instances/:instance_id/service_bindings/:id" do
+    it 'echoes the parameters in the credentials' do
+      put '/v2/service_instances/fakeIDThough/service_bindings/fakeBindingID', '{"parameters":{"nested":{"unicode":"\u2603"}}}'
+      expect(last_response.status).to eq(201)
+      expect(JSON.parse(last_response.body)['credentials']['parameters']).to eq({ 'nested' => { 'unicode' => "\u2603" } })
+    end
+  end
+
+  describe "GET /journal" do
+    it 'returns the requests made to the v2 API' do
+      header 'X-Broker-Api-Version', '2.5'
+      put '/v2/service_instances/fakeIDThough?accepts_incomplete=true', '{"plan_id":"fake-plan"}'
+      get '/env'
+
+      get '/journal'
+      expect(last_response.status).to eq(200)
+
+      entry = JSON.parse(last_response.body).last
+      expect(entry['method']).to eq('PUT')
+      expect(entry['path']).to eq('/v2/service_instances/fakeIDThough')
+      expect(entry['query']).to eq('accepts_incomplete=true')
+      expect(entry['header']['X-Broker-Api-Version']).to eq(['2.5'])
+      expect(entry['body']).to eq('{"plan_id":"fake-plan"}')
+    end
+
+    it 'keeps only the latest requests' do
+      (JOURNAL_LIMIT + 1).times { |i| get "/v2/catalog?n=#{i}" }
+
+      get '/journal'
+      journal = JSON.parse(last_response.body)
+      expect(journal.size).to eq(JOURNAL_LIMIT)
+      expect(journal.last['query']).to eq("n=#{JOURNAL_LIMIT}")
+    end
+  end
 end
@@ -33,4 +33,39 @@ describe ServiceBroker do
       expect(JSON.parse(last_response.body)).to be_empty
     end
   end
+
+  describe "PUT /v2/service_instances/:instance_id/service_bindings/:id" do
+    it 'echoes the parameters in the credentials' do
+      put '/v2/service_instances/fakeIDThough/service_bindings/fakeBindingID', '{"parameters":{"nested":{"unicode":"\u2603"}}}'
+      expect(last_response.status).to eq(201)
+      expect(JSON.parse(last_response.body)['credentials']['parameters']).to eq({ 'nested' => { 'unicode' => "\u2603" } })
+    end
+  end
+
+  describe "GET /journal" do
+    it 'returns the requests made to the v2 API' do
+      header 'X-Broker-Api-Version', '2.5'
+      put '/v2/service_instances/fakeIDThough?accepts_incomplete=true', '{"plan_id":"fake-plan"}'
+      get '/env'
+
+      get '/journal'
+      expect(last_response.status).to eq(200)
+
+      entry = JSON.parse(last_response.body).last
+      expect(entry['method']).to eq('PUT')
+      expect(entry['path']).to eq('/v2/service_instances/fakeIDThough')
+      expect(entry['query']).to eq('accepts_incomplete=true')
+      expect(entry['header']['X-Broker-Api-Version']).to eq(['2.5'])
+      expect(entry['body']).to eq('{"plan_id":"fake-plan"}')
+    end
+
+    it 'keeps only the latest requests' do
+      (JOURNAL_LIMIT + 1).times { |i| get "/v2/catalog?n=#{i}" }
+
+      get '/journal'
+      journal = JSON.parse(last_response.body)
+      expect(journal.size).to eq(JOURNAL_LIMIT)
+      expect(journal.last['query']).to eq("n=#{JOURNAL_LIMIT}")
+    end
+  end
 end
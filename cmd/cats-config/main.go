@@ -0,0 +1,95 @@
+// Command cats-config prints the documented schema of the CATS config,
+// validates a config file for a suite, or prints the effective config that a
+// suite would run with given $CONFIG, CATS_* environment variables and
+// -cats.* flags.
+//
+//	cats-config
+//	cats-config -validate integration_config.json -suite logging
+//	CATS_DEFAULT_TIMEOUT=60 cats-config -effective -suite apps -cats.apps_domain=example.com
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/config"
+)
+
+func main() {
+	validatePath := flag.String("validate", "", "validate this config file instead of printing the schema")
+	effective := flag.Bool("effective", false, "print the effective config, with secrets redacted, instead of the schema")
+	suite := flag.String("suite", "", "suite to check required keys for when validating")
+	flag.Parse()
+
+	if *validatePath != "" {
+		os.Exit(validate(*validatePath, *suite))
+	}
+
+	if *effective {
+		os.Exit(printEffective(*suite))
+	}
+
+	printSchema()
+}
+
+func printSchema() {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "KEY\tTYPE\tDEFAULT\tREQUIRED BY\tOVERRIDE\tDESCRIPTION")
+	for _, key := range config.Schema {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s, -%s%s\t%s\n",
+			key.Name, key.Type, defaultValue(key), requiredBy(key),
+			config.EnvironmentVariable(key), config.FlagPrefix, key.Name, key.Description)
+	}
+	writer.Flush()
+}
+
+func defaultValue(key config.Key) string {
+	if key.Default == nil {
+		return "-"
+	}
+	encoded, _ := json.Marshal(key.Default)
+	return string(encoded)
+}
+
+func requiredBy(key config.Key) string {
+	if len(key.RequiredBy) == 0 {
+		return "-"
+	}
+	if key.Required("") {
+		return "all suites"
+	}
+	return strings.Join(key.RequiredBy, ", ")
+}
+
+func printEffective(suite string) int {
+	effective, err := config.FromEnvironment(suite)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Print(effective.Effective())
+	return 0
+}
+
+func validate(path, suite string) int {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	err = config.Validate(data, suite)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Println(path, "is valid")
+	return 0
+}
@@ -0,0 +1,56 @@
+// Command cats-janitor deletes the orgs, quotas, users, security groups,
+// buildpacks and service brokers that aborted CATS runs left behind. It logs
+// in with the admin credentials from $CONFIG and only touches entities whose
+// names carry a CATS prefix and that are older than -max-age, so that runs in
+// progress against the same environment are left alone.
+//
+//	CONFIG=integration_config.json cats-janitor -dry-run
+//	CONFIG=integration_config.json cats-janitor -max-age 6h
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/config"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/janitor"
+)
+
+func main() {
+	maxAge := flag.Duration("max-age", janitor.DefaultMaxAge, "only delete entities created longer ago than this")
+	dryRun := flag.Bool("dry-run", false, "print what would be deleted without deleting it")
+	flag.Parse()
+
+	os.Exit(run(*maxAge, *dryRun))
+}
+
+func run(maxAge time.Duration, dryRun bool) int {
+	loaded, err := config.FromEnvironment("")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	j := &janitor.Janitor{
+		Api:               loaded.ApiEndpoint,
+		AdminUser:         loaded.AdminUser,
+		AdminPassword:     loaded.AdminPassword,
+		SkipSSLValidation: loaded.SkipSSLValidation,
+		MaxAge:            maxAge,
+		DryRun:            dryRun,
+		Out:               os.Stdout,
+	}
+
+	orphans, err := j.Run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if len(orphans) == 0 {
+		fmt.Println("nothing to clean up")
+	}
+	return 0
+}
@@ -0,0 +1,13 @@
+package appclient_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestAppclient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Appclient Suite")
+}
@@ -0,0 +1,194 @@
+// Package appclient makes HTTP requests to pushed apps from the test process
+// itself, instead of forking curl the way helpers.CurlApp does. Responses
+// carry the status code, headers and body, transport errors are returned as
+// errors, and each request can set its own headers, cookies, timeout and
+// retry policy.
+package appclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	catsconfig "github.com/cloudfoundry/cf-acceptance-tests/helpers/config"
+)
+
+const DefaultTimeout = 30 * time.Second
+
+// Client sends requests to apps routed on the apps domain.
+type Client struct {
+	AppsDomain string
+
+	// HTTPS sends requests to https:// routes unless a Request says otherwise.
+	HTTPS bool
+
+	// Timeout applies to requests that don't set their own.
+	Timeout time.Duration
+
+	// Retry applies to requests that don't set their own.
+	Retry RetryPolicy
+
+	// Transport is shared by every request. New configures it to honor
+	// skip_ssl_validation; when nil, http.DefaultTransport is used.
+	Transport *http.Transport
+}
+
+// Request describes a single request to an app. Only Path is required.
+type Request struct {
+	Method  string
+	Path    string
+	Header  http.Header
+	Cookies []*http.Cookie
+	Body    string
+
+	// HTTPS overrides the client's scheme when set.
+	HTTPS *bool
+
+	Timeout time.Duration
+	Retry   *RetryPolicy
+
+	// Quiet leaves the request out of the GinkgoWriter log, for callers that
+	// send many, like Load.
+	Quiet bool
+}
+
+// Response is what the app answered on the final attempt.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Cookies    []*http.Cookie
+	Body       string
+	Attempts   int
+}
+
+// New returns a client for the apps domain in the suite config.
+func New(config catsconfig.Config) *Client {
+	timeout := DefaultTimeout
+	if config.DefaultTimeout > 0 {
+		timeout = config.DefaultTimeout * time.Second
+	}
+
+	return &Client{
+		AppsDomain: config.AppsDomain,
+		Timeout:    timeout,
+		Retry:      NoRetry,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: config.SkipSSLValidation},
+		},
+	}
+}
+
+// URL returns the address of path on the app's route.
+func (c *Client) URL(appName, path string, https bool) string {
+	scheme := "http"
+	if https {
+		scheme = "https"
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return fmt.Sprintf("%s://%s.%s%s", scheme, appName, c.AppsDomain, path)
+}
+
+// Do sends the request, retrying it as its policy allows. Responses with
+// error status codes are not errors; only failures to get a response are.
+func (c *Client) Do(appName string, request Request) (Response, error) {
+	policy := c.Retry
+	if request.Retry != nil {
+		policy = *request.Retry
+	}
+
+	var response Response
+	var err error
+	for attempt := 1; ; attempt++ {
+		response, err = c.do(appName, request)
+		response.Attempts = attempt
+
+		if attempt >= policy.Attempts || !policy.retryable(response, err) {
+			return response, err
+		}
+		time.Sleep(policy.Interval)
+	}
+}
+
+// Get requests path from the app.
+func (c *Client) Get(appName, path string) (Response, error) {
+	return c.Do(appName, Request{Path: path})
+}
+
+// Body returns the body of path on the app whatever its status code, and
+// fails the spec if no response arrives. It is a drop-in replacement for
+// helpers.CurlApp.
+func (c *Client) Body(appName, path string) string {
+	response, err := c.Get(appName, path)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	return response.Body
+}
+
+func (c *Client) do(appName string, request Request) (Response, error) {
+	method := request.Method
+	if method == "" {
+		method = "GET"
+	}
+
+	https := c.HTTPS
+	if request.HTTPS != nil {
+		https = *request.HTTPS
+	}
+	url := c.URL(appName, request.Path, https)
+
+	timeout := c.Timeout
+	if request.Timeout > 0 {
+		timeout = request.Timeout
+	}
+
+	httpRequest, err := http.NewRequest(method, url, strings.NewReader(request.Body))
+	if err != nil {
+		return Response{}, err
+	}
+	for name, values := range request.Header {
+		httpRequest.Header[name] = values
+	}
+	for _, cookie := range request.Cookies {
+		httpRequest.AddCookie(cookie)
+	}
+
+	client := &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	if c.Transport != nil {
+		client.Transport = c.Transport
+	}
+
+	if !request.Quiet {
+		fmt.Fprintf(GinkgoWriter, "\n[%s]> %s %s\n", time.Now().UTC().Format("2006-01-02 15:04:05.00 (MST)"), method, url)
+	}
+
+	httpResponse, err := client.Do(httpRequest)
+	if err != nil {
+		return Response{}, fmt.Errorf("%s %s failed: %s", method, url, err)
+	}
+	defer httpResponse.Body.Close()
+
+	body, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return Response{}, fmt.Errorf("%s %s failed reading the body: %s", method, url, err)
+	}
+
+	return Response{
+		StatusCode: httpResponse.StatusCode,
+		Header:     httpResponse.Header,
+		Cookies:    httpResponse.Cookies(),
+		Body:       string(body),
+	}, nil
+}
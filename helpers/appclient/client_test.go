@@ -0,0 +1,197 @@
+package appclient_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/appclient"
+	catsconfig "github.com/cloudfoundry/cf-acceptance-tests/helpers/config"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// routeTo sends every request the client makes to the test server, whatever
+// the app's host name, the way the router would.
+func routeTo(client *appclient.Client, server *httptest.Server) {
+	client.Transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, network, server.Listener.Addr().String())
+	}
+}
+
+func newClient(skipSSLValidation bool) *appclient.Client {
+	return appclient.New(catsconfig.Config{
+		Config: helpers.Config{AppsDomain: "example.com", SkipSSLValidation: skipSSLValidation},
+	})
+}
+
+var _ = Describe("Client", func() {
+	var client *appclient.Client
+	var server *httptest.Server
+	var handler http.HandlerFunc
+
+	BeforeEach(func() {
+		client = newClient(false)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			handler(w, req)
+		}))
+		routeTo(client, server)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("builds app URLs on the apps domain", func() {
+		Expect(client.URL("dora", "/env", false)).To(Equal("http://dora.example.com/env"))
+		Expect(client.URL("dora", "env", true)).To(Equal("https://dora.example.com/env"))
+	})
+
+	It("returns the status, headers and body of the response", func() {
+		var host string
+		handler = func(w http.ResponseWriter, req *http.Request) {
+			host = req.Host
+			w.Header().Set("X-App", "dora")
+			w.WriteHeader(http.StatusTeapot)
+			w.Write([]byte("Hi, I'm Dora!"))
+		}
+
+		response, err := client.Get("dora", "/")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(host).To(Equal("dora.example.com"))
+		Expect(response.StatusCode).To(Equal(http.StatusTeapot))
+		Expect(response.Header.Get("X-App")).To(Equal("dora"))
+		Expect(response.Body).To(Equal("Hi, I'm Dora!"))
+		Expect(response.Attempts).To(Equal(1))
+	})
+
+	It("sends the method, headers, cookies and body of the request", func() {
+		var method, header, cookie, body string
+		handler = func(w http.ResponseWriter, req *http.Request) {
+			method = req.Method
+			header = req.Header.Get("X-Test")
+			if c, err := req.Cookie("JSESSIONID"); err == nil {
+				cookie = c.Value
+			}
+			data, _ := ioutil.ReadAll(req.Body)
+			body = string(data)
+			http.SetCookie(w, &http.Cookie{Name: "__VCAP_ID__", Value: "instance-id"})
+		}
+
+		response, err := client.Do("dora", appclient.Request{
+			Method:  "PUT",
+			Path:    "/session",
+			Header:  http.Header{"X-Test": {"value"}},
+			Cookies: []*http.Cookie{{Name: "JSESSIONID", Value: "session-id"}},
+			Body:    "payload",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(method).To(Equal("PUT"))
+		Expect(header).To(Equal("value"))
+		Expect(cookie).To(Equal("session-id"))
+		Expect(body).To(Equal("payload"))
+		Expect(response.Cookies).To(HaveLen(1))
+		Expect(response.Cookies[0].Value).To(Equal("instance-id"))
+	})
+
+	It("does not follow redirects", func() {
+		handler = func(w http.ResponseWriter, req *http.Request) {
+			http.Redirect(w, req, "/elsewhere", http.StatusFound)
+		}
+
+		response, err := client.Get("dora", "/")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusFound))
+		Expect(response.Header.Get("Location")).To(Equal("/elsewhere"))
+	})
+
+	It("returns an error naming the URL when the request times out", func() {
+		handler = func(w http.ResponseWriter, req *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		_, err := client.Do("dora", appclient.Request{Path: "/slow", Timeout: 50 * time.Millisecond})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("GET http://dora.example.com/slow failed:"))
+	})
+
+	Describe("retries", func() {
+		var requests int32
+
+		BeforeEach(func() {
+			atomic.StoreInt32(&requests, 0)
+			handler = func(w http.ResponseWriter, req *http.Request) {
+				if atomic.AddInt32(&requests, 1) < 3 {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write([]byte("ready"))
+			}
+		})
+
+		It("sends the request once by default", func() {
+			response, err := client.Get("dora", "/")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+			Expect(atomic.LoadInt32(&requests)).To(BeEquivalentTo(1))
+		})
+
+		It("retries until the expected status", func() {
+			policy := appclient.RetryUntilStatus(http.StatusOK, 5, time.Millisecond)
+
+			response, err := client.Do("dora", appclient.Request{Path: "/", Retry: &policy})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Body).To(Equal("ready"))
+			Expect(response.Attempts).To(Equal(3))
+		})
+
+		It("returns the last response when it runs out of attempts", func() {
+			client.Retry = appclient.RetryUntilStatus(http.StatusOK, 2, time.Millisecond)
+
+			response, err := client.Get("dora", "/")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+			Expect(response.Attempts).To(Equal(2))
+		})
+	})
+
+	Describe("HTTPS routes", func() {
+		var tlsServer *httptest.Server
+
+		BeforeEach(func() {
+			tlsServer = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Write([]byte("secure"))
+			}))
+		})
+
+		AfterEach(func() {
+			tlsServer.Close()
+		})
+
+		It("verifies certificates by default", func() {
+			routeTo(client, tlsServer)
+			client.HTTPS = true
+
+			_, err := client.Get("dora", "/")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("certificate"))
+		})
+
+		It("skips verification when skip_ssl_validation is set", func() {
+			client = newClient(true)
+			routeTo(client, tlsServer)
+			https := true
+
+			response, err := client.Do("dora", appclient.Request{Path: "/", HTTPS: &https})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Body).To(Equal("secure"))
+		})
+	})
+})
@@ -0,0 +1,79 @@
+package appclient
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+const (
+	// SessionCookie is the cookie that makes the router pin a client to an
+	// instance, by answering with VcapIDCookie.
+	SessionCookie = "JSESSIONID"
+
+	// VcapIDCookie names the instance the router sends the session to.
+	VcapIDCookie = "__VCAP_ID__"
+
+	// DefaultSessionAttempts is how many sessions InstanceSession opens at
+	// most, enough to reach any of a handful of instances.
+	DefaultSessionAttempts = 50
+)
+
+// InstanceSession returns cookies that make the router send requests to the
+// app's instance at index. It opens sessions until the router pins one to
+// that instance, so the app has to set SessionCookie on POST /session and
+// report its index at /env/CF_INSTANCE_INDEX, as dora does.
+func (c *Client) InstanceSession(appName string, index int, attempts int) ([]*http.Cookie, error) {
+	seen := map[int]bool{}
+	for attempt := 0; attempt < attempts; attempt++ {
+		session, err := c.Do(appName, Request{Method: "POST", Path: "/session"})
+		if err != nil {
+			return nil, err
+		}
+
+		cookies := stickyCookies(session.Cookies)
+		if len(cookies) != 2 {
+			return nil, fmt.Errorf("expected %s and %s cookies opening a session with %s, got %v", SessionCookie, VcapIDCookie, appName, session.Cookies)
+		}
+
+		reported, err := c.Do(appName, Request{Path: "/env/CF_INSTANCE_INDEX", Cookies: cookies})
+		if err != nil {
+			return nil, err
+		}
+
+		instanceIndex, err := strconv.Atoi(strings.TrimSpace(reported.Body))
+		if err != nil {
+			return nil, fmt.Errorf("%s reported an invalid instance index %q", appName, reported.Body)
+		}
+		if instanceIndex == index {
+			return cookies, nil
+		}
+		seen[instanceIndex] = true
+	}
+
+	return nil, fmt.Errorf("no session with %s reached instance %d in %d attempts, only %v", appName, index, attempts, seen)
+}
+
+// KillInstance kills the app's instance at index with SIGKILL through dora's
+// /sigterm/KILL, so that it crashes. The request itself usually fails, as the
+// instance dies before answering.
+func (c *Client) KillInstance(appName string, index int) {
+	cookies, err := c.InstanceSession(appName, index, DefaultSessionAttempts)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	c.Do(appName, Request{Path: "/sigterm/KILL", Cookies: cookies, Timeout: 5 * time.Second, Retry: &NoRetry})
+}
+
+func stickyCookies(cookies []*http.Cookie) []*http.Cookie {
+	sticky := []*http.Cookie{}
+	for _, cookie := range cookies {
+		if cookie.Name == SessionCookie || cookie.Name == VcapIDCookie {
+			sticky = append(sticky, &http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		}
+	}
+	return sticky
+}
@@ -0,0 +1,94 @@
+package appclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/appclient"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeRouter pins sessions to three instances of an app in turn, like the
+// router does for an app that sets JSESSIONID.
+type fakeRouter struct {
+	lock     sync.Mutex
+	sessions int
+	killed   []string
+}
+
+func (r *fakeRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	instance := "unpinned"
+	if cookie, err := req.Cookie(appclient.VcapIDCookie); err == nil {
+		instance = cookie.Value
+	}
+
+	switch {
+	case req.Method == "POST" && req.URL.Path == "/session":
+		instance = fmt.Sprintf("instance-%d", r.sessions%3)
+		r.sessions++
+		http.SetCookie(w, &http.Cookie{Name: appclient.SessionCookie, Value: "session"})
+		http.SetCookie(w, &http.Cookie{Name: appclient.VcapIDCookie, Value: instance})
+	case req.URL.Path == "/env/CF_INSTANCE_INDEX":
+		fmt.Fprint(w, strings.TrimPrefix(instance, "instance-"))
+	case req.URL.Path == "/sigterm/KILL":
+		r.killed = append(r.killed, instance)
+	}
+}
+
+var _ = Describe("Instances", func() {
+	var client *appclient.Client
+	var server *httptest.Server
+	var router *fakeRouter
+
+	BeforeEach(func() {
+		client = newClient(false)
+		router = &fakeRouter{}
+		server = httptest.NewServer(router)
+		routeTo(client, server)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("InstanceSession", func() {
+		It("opens sessions until one is pinned to the instance", func() {
+			cookies, err := client.InstanceSession("dora", 2, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(router.sessions).To(Equal(3))
+
+			response, err := client.Do("dora", appclient.Request{Path: "/env/CF_INSTANCE_INDEX", Cookies: cookies})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Body).To(Equal("2"))
+		})
+
+		It("gives up after the given number of sessions", func() {
+			_, err := client.InstanceSession("dora", 5, 4)
+			Expect(err).To(MatchError(ContainSubstring("no session with dora reached instance 5 in 4 attempts")))
+		})
+
+		It("fails when the router doesn't pin sessions", func() {
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				fmt.Fprint(w, "0")
+			})
+
+			_, err := client.InstanceSession("dora", 0, 4)
+			Expect(err).To(MatchError(ContainSubstring("expected JSESSIONID and __VCAP_ID__ cookies")))
+		})
+	})
+
+	Describe("KillInstance", func() {
+		It("sends the kill to the instance at the index", func() {
+			client.KillInstance("dora", 1)
+			Expect(router.killed).To(Equal([]string{"instance-1"}))
+		})
+	})
+})
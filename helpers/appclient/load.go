@@ -0,0 +1,145 @@
+package appclient
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+)
+
+// Load sends requests to an app in the background and records every result,
+// e.g. to check that no request failed while the app's route moved.
+type Load struct {
+	client   *Client
+	appName  string
+	path     string
+	interval time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+
+	lock    sync.Mutex
+	results []LoadResult
+}
+
+// LoadResult is a probe sent by a Load, with when it was sent.
+type LoadResult struct {
+	ProbeResult
+	SentAt time.Time
+}
+
+// Failed reports whether the request got no response, a router error or a
+// non-2xx status from the app.
+func (r LoadResult) Failed() bool {
+	return r.Err != nil || r.RouterError() != "" || r.StatusCode/100 != 2
+}
+
+// StartLoad probes path on the app every interval, one request at a time,
+// until Stop is called. Requests are not logged individually.
+func (c *Client) StartLoad(appName, path string, interval time.Duration) *Load {
+	load := &Load{
+		client:   c,
+		appName:  appName,
+		path:     path,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go load.run()
+	return load
+}
+
+func (l *Load) run() {
+	defer GinkgoRecover()
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		sentAt := time.Now()
+		response, err := l.client.Do(l.appName, Request{Path: l.path, Retry: &NoRetry, Quiet: true})
+
+		l.lock.Lock()
+		l.results = append(l.results, LoadResult{
+			ProbeResult: ProbeResult{Response: response, URL: l.client.URL(l.appName, l.path, l.client.HTTPS), Err: err},
+			SentAt:      sentAt,
+		})
+		l.lock.Unlock()
+
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops sending requests, waits for the one in flight and returns every
+// result. It may be called more than once; the first call logs a summary.
+func (l *Load) Stop() []LoadResult {
+	stopping := false
+	l.lock.Lock()
+	select {
+	case <-l.stop:
+	default:
+		close(l.stop)
+		stopping = true
+	}
+	l.lock.Unlock()
+
+	<-l.done
+	results := l.Results()
+	if stopping {
+		fmt.Fprintf(GinkgoWriter, "\nsent %d requests to %s, %d failed\n", len(results), l.client.URL(l.appName, l.path, l.client.HTTPS), len(l.Failures()))
+	}
+	return results
+}
+
+// Results returns the results recorded so far, oldest first.
+func (l *Load) Results() []LoadResult {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return append([]LoadResult{}, l.results...)
+}
+
+// Failures returns the results that Failed, oldest first.
+func (l *Load) Failures() []LoadResult {
+	failures := []LoadResult{}
+	for _, result := range l.Results() {
+		if result.Failed() {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// FailuresBetween returns the results that Failed among those sent from start
+// to end, oldest first.
+func (l *Load) FailuresBetween(start, end time.Time) []LoadResult {
+	failures := []LoadResult{}
+	for _, result := range l.Failures() {
+		if !result.SentAt.Before(start) && !result.SentAt.After(end) {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// Bodies returns the last count bodies received from the app, oldest first,
+// e.g. to wait until only the new version of an app answers.
+func (l *Load) Bodies(count int) []string {
+	bodies := []string{}
+	for _, result := range l.Results() {
+		if result.Err == nil && result.RouterError() == "" {
+			bodies = append(bodies, strings.TrimSpace(result.Body))
+		}
+	}
+	if len(bodies) > count {
+		bodies = bodies[len(bodies)-count:]
+	}
+	return bodies
+}
@@ -0,0 +1,123 @@
+package appclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/appclient"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load", func() {
+	var client *appclient.Client
+	var server *httptest.Server
+
+	var lock sync.Mutex
+	var statuses []int
+	var body string
+
+	// serve answers the next requests with the given statuses in turn, then
+	// with 200 and the current body.
+	serve := func(next ...int) {
+		lock.Lock()
+		defer lock.Unlock()
+		statuses = next
+	}
+
+	setBody := func(next string) {
+		lock.Lock()
+		defer lock.Unlock()
+		body = next
+	}
+
+	BeforeEach(func() {
+		statuses = nil
+		body = "blue"
+
+		client = newClient(false)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			if len(statuses) > 0 {
+				status := statuses[0]
+				statuses = statuses[1:]
+				if status == http.StatusBadGateway {
+					w.Header().Set("X-Cf-Routererror", appclient.RouterEndpointFailure)
+				}
+				w.WriteHeader(status)
+				return
+			}
+			fmt.Fprintln(w, body)
+		}))
+		routeTo(client, server)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("records every response until stopped", func() {
+		load := client.StartLoad("dora", "/env/COLOR", time.Millisecond)
+		Eventually(func() []string { return load.Bodies(3) }).Should(Equal([]string{"blue", "blue", "blue"}))
+
+		setBody("green")
+		Eventually(func() []string { return load.Bodies(3) }).Should(Equal([]string{"green", "green", "green"}))
+
+		results := load.Stop()
+		Expect(len(results)).To(BeNumerically(">=", 6))
+		Expect(results[0].URL).To(Equal("http://dora.example.com/env/COLOR"))
+		Expect(results[0].SentAt).To(BeTemporally("<=", results[len(results)-1].SentAt))
+		Expect(load.Failures()).To(BeEmpty())
+
+		Consistently(func() []appclient.LoadResult { return load.Results() }, 20*time.Millisecond).Should(HaveLen(len(results)))
+	})
+
+	It("reports errors from the app and the router as failures", func() {
+		serve(http.StatusInternalServerError, http.StatusBadGateway)
+
+		load := client.StartLoad("dora", "/env/COLOR", time.Millisecond)
+		Eventually(func() []string { return load.Bodies(1) }).Should(Equal([]string{"blue"}))
+		load.Stop()
+
+		failures := load.Failures()
+		Expect(failures).To(HaveLen(2))
+		Expect(failures[0].StatusCode).To(Equal(http.StatusInternalServerError))
+		Expect(failures[1].RouterError()).To(Equal(appclient.RouterEndpointFailure))
+	})
+
+	It("reports the failures sent within a window", func() {
+		serve(http.StatusInternalServerError, http.StatusInternalServerError)
+
+		load := client.StartLoad("dora", "/env/COLOR", time.Millisecond)
+		Eventually(func() []string { return load.Bodies(1) }).Should(Equal([]string{"blue"}))
+		load.Stop()
+
+		failures := load.Failures()
+		Expect(failures).To(HaveLen(2))
+		Expect(load.FailuresBetween(failures[0].SentAt, failures[0].SentAt)).To(Equal(failures[:1]))
+		Expect(load.FailuresBetween(failures[1].SentAt, time.Now())).To(Equal(failures[1:]))
+		Expect(load.FailuresBetween(time.Now(), time.Now())).To(BeEmpty())
+	})
+
+	It("reports requests that got no response as failures", func() {
+		load := client.StartLoad("dora", "/env/COLOR", time.Millisecond)
+		Eventually(func() []appclient.LoadResult { return load.Results() }).ShouldNot(BeEmpty())
+		server.Close()
+
+		Eventually(func() []appclient.LoadResult { return load.Failures() }).ShouldNot(BeEmpty())
+		load.Stop()
+		Expect(load.Failures()[0].Err).To(HaveOccurred())
+	})
+
+	It("can be stopped more than once", func() {
+		load := client.StartLoad("dora", "/env/COLOR", time.Millisecond)
+		first := load.Stop()
+		Expect(load.Stop()).To(Equal(first))
+	})
+})
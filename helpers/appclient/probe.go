@@ -0,0 +1,78 @@
+package appclient
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Errors the router reports in the X-Cf-Routererror header.
+const (
+	RouterUnknownRoute    = "unknown_route"
+	RouterEndpointFailure = "endpoint_failure"
+)
+
+// Older routers don't set X-Cf-Routererror, so their errors are recognized
+// by body.
+var (
+	unknownRouteBody    = regexp.MustCompile(`Requested route \('[^']*'\) does not exist`)
+	endpointFailureBody = regexp.MustCompile(`Registered endpoint failed to handle the request`)
+)
+
+// ProbeResult is the outcome of a single request to an app: either a
+// response, from the app or the router, or the error that prevented one.
+type ProbeResult struct {
+	Response
+	URL string
+	Err error
+}
+
+// Probe requests path from the app once, without retries.
+func (c *Client) Probe(appName, path string) ProbeResult {
+	response, err := c.Do(appName, Request{Path: path, Retry: &NoRetry})
+	return ProbeResult{Response: response, URL: c.URL(appName, path, c.HTTPS), Err: err}
+}
+
+// Probing returns a function for Eventually and Consistently that probes the
+// app each time it is called:
+//
+//	Eventually(appClient.Probing(appName, "/"), CF_PUSH_TIMEOUT).Should(BeReachableWithBody(ContainSubstring("Hi, I'm Dora!")))
+func (c *Client) Probing(appName, path string) func() ProbeResult {
+	return func() ProbeResult {
+		return c.Probe(appName, path)
+	}
+}
+
+// RouterError returns the error the router answered with instead of the app,
+// e.g. RouterUnknownRoute, or the empty string if the app answered.
+func (r ProbeResult) RouterError() string {
+	if r.Err != nil {
+		return ""
+	}
+
+	if routerError := r.Header.Get("X-Cf-Routererror"); routerError != "" {
+		return routerError
+	}
+	if r.StatusCode == 404 && unknownRouteBody.MatchString(r.Body) {
+		return RouterUnknownRoute
+	}
+	if r.StatusCode == 502 && endpointFailureBody.MatchString(r.Body) {
+		return RouterEndpointFailure
+	}
+	return ""
+}
+
+// Unroutable reports whether the router has no route to the app, as opposed
+// to the app itself answering 404.
+func (r ProbeResult) Unroutable() bool {
+	return r.RouterError() == RouterUnknownRoute
+}
+
+func (r ProbeResult) String() string {
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("no response: %s", r.Err)
+	case r.RouterError() != "":
+		return fmt.Sprintf("router error %s (%d) from %s:\n%s", r.RouterError(), r.StatusCode, r.URL, r.Body)
+	}
+	return fmt.Sprintf("status %d from %s:\n%s", r.StatusCode, r.URL, r.Body)
+}
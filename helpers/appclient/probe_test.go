@@ -0,0 +1,69 @@
+package appclient_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/appclient"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func result(statusCode int, header http.Header, body string) appclient.ProbeResult {
+	return appclient.ProbeResult{
+		Response: appclient.Response{StatusCode: statusCode, Header: header, Body: body},
+		URL:      "http://dora.example.com/",
+	}
+}
+
+var _ = Describe("Probes", func() {
+	Describe("RouterError", func() {
+		It("reads the router's error header", func() {
+			probe := result(404, http.Header{"X-Cf-Routererror": {"unknown_route"}}, "")
+			Expect(probe.RouterError()).To(Equal(appclient.RouterUnknownRoute))
+			Expect(probe.Unroutable()).To(BeTrue())
+		})
+
+		It("recognizes the bodies of routers that don't set the header", func() {
+			unknownRoute := result(404, http.Header{}, "404 Not Found: Requested route ('dora.example.com') does not exist.\n")
+			Expect(unknownRoute.RouterError()).To(Equal(appclient.RouterUnknownRoute))
+
+			endpointFailure := result(502, http.Header{}, "502 Bad Gateway: Registered endpoint failed to handle the request.\n")
+			Expect(endpointFailure.RouterError()).To(Equal(appclient.RouterEndpointFailure))
+		})
+
+		It("treats other 404s as coming from the app", func() {
+			probe := result(404, http.Header{}, "Not found: /missing")
+			Expect(probe.RouterError()).To(BeEmpty())
+			Expect(probe.Unroutable()).To(BeFalse())
+		})
+
+		It("is empty when there was no response", func() {
+			probe := appclient.ProbeResult{Err: errors.New("connection refused")}
+			Expect(probe.RouterError()).To(BeEmpty())
+			Expect(probe.String()).To(Equal("no response: connection refused"))
+		})
+	})
+
+	Describe("Probing", func() {
+		It("sends a single request each time it is called", func() {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				requests++
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			defer server.Close()
+
+			client := newClient(false)
+			client.Retry = appclient.RetryUntilStatus(http.StatusOK, 5, 0)
+			routeTo(client, server)
+
+			probe := client.Probing("dora", "/")
+			Expect(probe().StatusCode).To(Equal(http.StatusNotFound))
+			Expect(probe().URL).To(Equal("http://dora.example.com/"))
+			Expect(requests).To(Equal(2))
+		})
+	})
+})
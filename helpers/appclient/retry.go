@@ -0,0 +1,44 @@
+package appclient
+
+import "time"
+
+// RetryPolicy decides whether a request is sent again. A request is sent at
+// most Attempts times, Interval apart, for as long as Retryable returns true.
+type RetryPolicy struct {
+	Attempts  int
+	Interval  time.Duration
+	Retryable func(Response, error) bool
+}
+
+// NoRetry sends every request exactly once.
+var NoRetry = RetryPolicy{Attempts: 1}
+
+// RetryOnError retries requests that got no response at all, e.g. while a
+// route is still being registered with the router.
+func RetryOnError(attempts int, interval time.Duration) RetryPolicy {
+	return RetryPolicy{
+		Attempts: attempts,
+		Interval: interval,
+		Retryable: func(_ Response, err error) bool {
+			return err != nil
+		},
+	}
+}
+
+// RetryUntilStatus retries until the app answers with the status code.
+func RetryUntilStatus(statusCode, attempts int, interval time.Duration) RetryPolicy {
+	return RetryPolicy{
+		Attempts: attempts,
+		Interval: interval,
+		Retryable: func(response Response, err error) bool {
+			return err != nil || response.StatusCode != statusCode
+		},
+	}
+}
+
+func (p RetryPolicy) retryable(response Response, err error) bool {
+	if p.Retryable == nil {
+		return err != nil
+	}
+	return p.Retryable(response, err)
+}
@@ -0,0 +1,19 @@
+package ccapi_test
+
+import (
+	"testing"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	fake = fakes.InstallForSuite()
+)
+
+func TestCcapi(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CC API Suite")
+}
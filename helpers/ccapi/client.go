@@ -0,0 +1,138 @@
+package ccapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+)
+
+// Error is returned when the Cloud Controller answers a request with an error body.
+type Error struct {
+	Method   string
+	Endpoint string
+
+	Code        int
+	ErrorCode   string
+	Title       string
+	Description string
+}
+
+func (e *Error) Error() string {
+	name := e.ErrorCode
+	if name == "" {
+		name = e.Title
+	}
+	return fmt.Sprintf("%s %s failed: %s (%d): %s", e.Method, e.Endpoint, name, e.Code, e.Description)
+}
+
+// NotFound reports whether the resource the request was for does not exist,
+// e.g. CF-AppNotFound or CF-ResourceNotFound.
+func (e *Error) NotFound() bool {
+	return strings.HasSuffix(e.ErrorCode, "NotFound") || strings.HasSuffix(e.Title, "NotFound")
+}
+
+type errorResponse struct {
+	Code        int    `json:"code"`
+	ErrorCode   string `json:"error_code"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+
+	Errors []struct {
+		Code   int    `json:"code"`
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+	} `json:"errors"`
+}
+
+// Request sends a request to the currently targeted Cloud Controller, like
+// cf.ApiRequest, and decodes the body into response. Unlike cf.ApiRequest it
+// accepts empty bodies and reports Cloud Controller error responses as *Error.
+func Request(method, endpoint string, response interface{}, data ...string) error {
+	args := []string{"curl", endpoint, "-X", method}
+	if len(data) > 0 {
+		args = append(args, "-d", strings.Join(data, ""))
+	}
+
+	session := cf.Cf(args...).Wait(cf.CF_API_TIMEOUT)
+	if session.ExitCode() != 0 {
+		return fmt.Errorf("%s %s failed: cf curl exited with %d:\n%s", method, endpoint, session.ExitCode(), session.Out.Contents())
+	}
+
+	body := session.Out.Contents()
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return nil
+	}
+
+	if err := checkForError(method, endpoint, body); err != nil {
+		return err
+	}
+
+	if response == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(body, response); err != nil {
+		return fmt.Errorf("%s %s returned an invalid response: %s\n%s", method, endpoint, err, body)
+	}
+	return nil
+}
+
+func checkForError(method, endpoint string, body []byte) error {
+	var errResponse errorResponse
+	if json.Unmarshal(body, &errResponse) != nil {
+		return nil
+	}
+
+	if len(errResponse.Errors) > 0 {
+		first := errResponse.Errors[0]
+		return &Error{
+			Method:      method,
+			Endpoint:    endpoint,
+			Code:        first.Code,
+			Title:       first.Title,
+			Description: first.Detail,
+		}
+	}
+
+	if errResponse.ErrorCode == "" && errResponse.Title == "" {
+		return nil
+	}
+
+	return &Error{
+		Method:      method,
+		Endpoint:    endpoint,
+		Code:        errResponse.Code,
+		ErrorCode:   errResponse.ErrorCode,
+		Title:       errResponse.Title,
+		Description: errResponse.Description,
+	}
+}
+
+func Get(endpoint string, response interface{}) {
+	ExpectWithOffset(1, Request("GET", endpoint, response)).To(Succeed())
+}
+
+func Post(endpoint string, response interface{}, data ...string) {
+	ExpectWithOffset(1, Request("POST", endpoint, response, data...)).To(Succeed())
+}
+
+func Put(endpoint string, response interface{}, data ...string) {
+	ExpectWithOffset(1, Request("PUT", endpoint, response, data...)).To(Succeed())
+}
+
+func Delete(endpoint string) {
+	ExpectWithOffset(1, Request("DELETE", endpoint, nil)).To(Succeed())
+}
+
+// deleteIfExists deletes a resource, ignoring that it is already gone.
+func deleteIfExists(endpoint string) error {
+	err := Request("DELETE", endpoint, nil)
+	if ccError, ok := err.(*Error); ok && ccError.NotFound() {
+		return nil
+	}
+	return err
+}
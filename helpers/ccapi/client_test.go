@@ -0,0 +1,94 @@
+package ccapi_test
+
+import (
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Request", func() {
+	It("decodes the response body", func() {
+		fake.CloudController.RouteToJSON("GET", "/v2/apps/app-guid", 200, map[string]interface{}{
+			"metadata": map[string]string{"guid": "app-guid"},
+			"entity":   map[string]interface{}{"name": "dora", "instances": 2},
+		})
+
+		var app ccapi.App
+		Expect(ccapi.Request("GET", "/v2/apps/app-guid", &app)).To(Succeed())
+		Expect(app.Metadata.Guid).To(Equal("app-guid"))
+		Expect(app.Entity.Name).To(Equal("dora"))
+		Expect(app.Entity.Instances).To(Equal(2))
+	})
+
+	It("sends the method and body through cf curl", func() {
+		fake.CloudController.RouteToJSON("PUT", "/v2/buildpacks/bp-guid", 201, map[string]interface{}{})
+
+		Expect(ccapi.Request("PUT", "/v2/buildpacks/bp-guid", nil, `{"enabled":`, `false}`)).To(Succeed())
+
+		Expect(fake.CF.InvocationsOf("cf curl")[0].Args).To(Equal([]string{
+			"curl", "/v2/buildpacks/bp-guid", "-X", "PUT", "-d", `{"enabled":false}`,
+		}))
+	})
+
+	It("accepts an empty response body", func() {
+		fake.CF.Handle("cf curl", fakes.Output(""))
+
+		var app ccapi.App
+		Expect(ccapi.Request("DELETE", "/v2/apps/app-guid", &app)).To(Succeed())
+	})
+
+	It("reports v2 error responses", func() {
+		fake.CloudController.RouteToError("GET", "/v2/apps/missing", 404, "CF-AppNotFound", "The app could not be found: missing")
+
+		err := ccapi.Request("GET", "/v2/apps/missing", nil)
+		Expect(err).To(HaveOccurred())
+
+		ccError, ok := err.(*ccapi.Error)
+		Expect(ok).To(BeTrue())
+		Expect(ccError.ErrorCode).To(Equal("CF-AppNotFound"))
+		Expect(ccError.Description).To(Equal("The app could not be found: missing"))
+		Expect(ccError.NotFound()).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("GET /v2/apps/missing failed"))
+	})
+
+	It("reports v3 error responses", func() {
+		fake.CloudController.RouteToJSON("POST", "/v3/apps", 422, map[string]interface{}{
+			"errors": []map[string]interface{}{
+				{"code": 10008, "title": "CF-UnprocessableEntity", "detail": "name must be unique in space"},
+			},
+		})
+
+		err := ccapi.Request("POST", "/v3/apps", nil, `{"name":"dora"}`)
+		Expect(err).To(MatchError(ContainSubstring("name must be unique in space")))
+		Expect(err.(*ccapi.Error).NotFound()).To(BeFalse())
+	})
+
+	It("reports when cf curl fails", func() {
+		fake.CF.Handle("cf curl", fakes.Failure(1, "Not logged in."))
+
+		err := ccapi.Request("GET", "/v2/apps", nil)
+		Expect(err).To(MatchError(ContainSubstring("Not logged in.")))
+	})
+
+	It("reports undecodable responses", func() {
+		fake.CF.Handle("cf curl", fakes.Output("<html>502 Bad Gateway</html>"))
+
+		var app ccapi.App
+		err := ccapi.Request("GET", "/v2/apps/app-guid", &app)
+		Expect(err).To(MatchError(ContainSubstring("invalid response")))
+	})
+
+	Describe("Get", func() {
+		It("fails the spec on errors", func() {
+			fake.CloudController.RouteToError("GET", "/v2/apps/missing", 404, "CF-AppNotFound", "The app could not be found: missing")
+
+			failures := InterceptGomegaFailures(func() {
+				ccapi.Get("/v2/apps/missing", nil)
+			})
+			Expect(failures).To(HaveLen(1))
+			Expect(failures[0]).To(ContainSubstring("CF-AppNotFound"))
+		})
+	})
+})
@@ -0,0 +1,122 @@
+package ccapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+
+	. "github.com/onsi/gomega"
+)
+
+type page struct {
+	NextUrl    string `json:"next_url"`
+	Pagination *struct {
+		Next *struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"pagination"`
+	Resources []json.RawMessage `json:"resources"`
+}
+
+// next returns the endpoint of the next page, if any. v3 links to it with an
+// absolute URL, of which cf curl takes only the path and query.
+func (p page) next() (string, error) {
+	if p.Pagination == nil {
+		return p.NextUrl, nil
+	}
+	if p.Pagination.Next == nil {
+		return "", nil
+	}
+
+	href, err := url.Parse(p.Pagination.Next.Href)
+	if err != nil {
+		return "", fmt.Errorf("invalid pagination.next: %s", err)
+	}
+	return href.RequestURI(), nil
+}
+
+// Iterator walks a v2 or v3 list endpoint one resource at a time, fetching
+// the next page (next_url or pagination.next) only when it is needed.
+//
+//	it := ccapi.NewIterator("/v2/apps")
+//	for it.Next() {
+//		var app ccapi.App
+//		it.Decode(&app)
+//	}
+//	Expect(it.Err()).NotTo(HaveOccurred())
+type Iterator struct {
+	next      string
+	resources []json.RawMessage
+	current   json.RawMessage
+	err       error
+}
+
+func NewIterator(endpoint string) *Iterator {
+	return &Iterator{next: endpoint}
+}
+
+// Next advances to the next resource. It returns false once every page has
+// been read or a request failed; check Err to tell the two apart.
+func (it *Iterator) Next() bool {
+	for len(it.resources) == 0 {
+		if it.err != nil || it.next == "" {
+			return false
+		}
+
+		var p page
+		if err := Request("GET", it.next, &p); err != nil {
+			it.err = err
+			return false
+		}
+
+		next, err := p.next()
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.resources = p.Resources
+		it.next = next
+	}
+
+	it.current, it.resources = it.resources[0], it.resources[1:]
+	return true
+}
+
+// Decode unmarshals the current resource.
+func (it *Iterator) Decode(resource interface{}) error {
+	if err := json.Unmarshal(it.current, resource); err != nil {
+		it.err = fmt.Errorf("invalid resource: %s\n%s", err, it.current)
+		return it.err
+	}
+	return nil
+}
+
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// ListAll fetches every page of a list endpoint and appends the resources to
+// the slice pointed to by resources.
+func ListAll(endpoint string, resources interface{}) {
+	ExpectWithOffset(1, listAll(endpoint, resources)).To(Succeed())
+}
+
+func listAll(endpoint string, resources interface{}) error {
+	slice := reflect.ValueOf(resources)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("ccapi.ListAll: resources must be a pointer to a slice, got %T", resources)
+	}
+	slice = slice.Elem()
+
+	it := NewIterator(endpoint)
+	for it.Next() {
+		resource := reflect.New(slice.Type().Elem())
+		if err := it.Decode(resource.Interface()); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, resource.Elem()))
+	}
+
+	return it.Err()
+}
@@ -0,0 +1,105 @@
+package ccapi_test
+
+import (
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pagination", func() {
+	Describe("Iterator", func() {
+		It("walks v2 pages through next_url", func() {
+			fake.CloudController.RouteToPages("/v2/apps",
+				[]interface{}{appResource("guid-1", "app-1")},
+				[]interface{}{appResource("guid-2", "app-2")},
+			)
+
+			guids := []string{}
+			it := ccapi.NewIterator("/v2/apps")
+			for it.Next() {
+				var app ccapi.App
+				Expect(it.Decode(&app)).To(Succeed())
+				guids = append(guids, app.Metadata.Guid)
+			}
+
+			Expect(it.Err()).NotTo(HaveOccurred())
+			Expect(guids).To(Equal([]string{"guid-1", "guid-2"}))
+		})
+
+		It("walks v3 pages through pagination.next", func() {
+			fake.CloudController.RouteToV3Pages("/v3/apps",
+				[]interface{}{map[string]string{"guid": "guid-1"}},
+				[]interface{}{map[string]string{"guid": "guid-2"}},
+			)
+
+			guids := []string{}
+			it := ccapi.NewIterator("/v3/apps")
+			for it.Next() {
+				var app ccapi.V3App
+				Expect(it.Decode(&app)).To(Succeed())
+				guids = append(guids, app.Guid)
+			}
+
+			Expect(it.Err()).NotTo(HaveOccurred())
+			Expect(guids).To(Equal([]string{"guid-1", "guid-2"}))
+			Expect(fake.CF.InvocationsOf("cf curl")[1].Args[1]).To(Equal("/v3/apps?page=2"))
+		})
+
+		It("only fetches the pages it needs", func() {
+			fake.CloudController.RouteToPages("/v2/apps",
+				[]interface{}{appResource("guid-1", "app-1")},
+				[]interface{}{appResource("guid-2", "app-2")},
+			)
+
+			it := ccapi.NewIterator("/v2/apps")
+			Expect(it.Next()).To(BeTrue())
+			Expect(fake.CloudController.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("skips empty pages", func() {
+			fake.CloudController.RouteToPages("/v2/apps",
+				[]interface{}{},
+				[]interface{}{appResource("guid-1", "app-1")},
+			)
+
+			it := ccapi.NewIterator("/v2/apps")
+			Expect(it.Next()).To(BeTrue())
+			Expect(it.Next()).To(BeFalse())
+			Expect(it.Err()).NotTo(HaveOccurred())
+		})
+
+		It("stops and reports the error when a page can't be fetched", func() {
+			fake.CloudController.RouteToError("GET", "/v2/apps", 401, "CF-InvalidAuthToken", "Invalid Auth Token")
+
+			it := ccapi.NewIterator("/v2/apps")
+			Expect(it.Next()).To(BeFalse())
+			Expect(it.Err()).To(MatchError(ContainSubstring("CF-InvalidAuthToken")))
+		})
+	})
+
+	Describe("ListAll", func() {
+		It("follows next_url until the last page", func() {
+			fake.CloudController.RouteToPages("/v2/apps",
+				[]interface{}{appResource("guid-1", "app-1"), appResource("guid-2", "app-2")},
+				[]interface{}{appResource("guid-3", "app-3")},
+			)
+
+			var apps []ccapi.App
+			ccapi.ListAll("/v2/apps?results-per-page=2", &apps)
+
+			Expect(apps).To(HaveLen(3))
+			Expect(apps[2].Metadata.Guid).To(Equal("guid-3"))
+			Expect(fake.CloudController.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("rejects anything but a pointer to a slice", func() {
+			var app ccapi.App
+			failures := InterceptGomegaFailures(func() {
+				ccapi.ListAll("/v2/apps", &app)
+			})
+			Expect(failures).To(HaveLen(1))
+			Expect(failures[0]).To(ContainSubstring("pointer to a slice"))
+		})
+	})
+})
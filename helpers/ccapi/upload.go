@@ -0,0 +1,230 @@
+package ccapi
+
+import (
+	"archive/zip"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+)
+
+// cfHomeConfig is the part of $CF_HOME/.cf/config.json that requests made
+// outside of `cf curl` need.
+type cfHomeConfig struct {
+	Target      string
+	SSLDisabled bool
+}
+
+// UploadPackage zips an app directory, e.g. one of the assets.Assets paths,
+// uploads it as the bits of a v3 package and waits up to timeout for the
+// package to become READY.
+func UploadPackage(packageGuid, directory string, timeout time.Duration) Package {
+	ExpectWithOffset(1, UploadPackageBits(packageGuid, directory)).To(Succeed())
+
+	pkg, err := WaitForPackage(packageGuid, timeout)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	return pkg
+}
+
+// UploadPackageBits zips directory and uploads it to
+// /v3/packages/:guid/upload as the currently targeted user. `cf curl` can't
+// send multipart bodies, so the request is made directly against the API
+// endpoint in $CF_HOME with a fresh token from `cf oauth-token`.
+func UploadPackageBits(packageGuid, directory string) error {
+	endpoint := fmt.Sprintf("/v3/packages/%s/upload", packageGuid)
+
+	homeConfig, err := readCfHomeConfig()
+	if err != nil {
+		return err
+	}
+
+	token, err := oauthToken()
+	if err != nil {
+		return err
+	}
+
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		part, err := form.CreateFormFile("bits", "package.zip")
+		if err == nil {
+			err = zipDirectory(directory, part)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	request, err := http.NewRequest("POST", strings.TrimRight(homeConfig.Target, "/")+endpoint, body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", token)
+	request.Header.Set("Content-Type", form.FormDataContentType())
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: homeConfig.SSLDisabled},
+		},
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("POST %s failed: %s", endpoint, err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if err := checkForError("POST", endpoint, responseBody); err != nil {
+		return err
+	}
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("POST %s failed with status %d:\n%s", endpoint, response.StatusCode, responseBody)
+	}
+	return nil
+}
+
+// WaitForPackage polls a package until it is READY, returning an error with
+// the package's error message if it FAILED.
+func WaitForPackage(guid string, timeout time.Duration) (Package, error) {
+	var pkg Package
+	err := pollWithBackoff(timeout, func() (bool, error) {
+		err := Request("GET", "/v3/packages/"+guid, &pkg)
+		if err != nil {
+			return false, err
+		}
+
+		switch pkg.State {
+		case "READY":
+			return true, nil
+		case "FAILED":
+			return false, fmt.Errorf("package %s failed: %s", guid, pkg.Error)
+		}
+		return false, nil
+	})
+	return pkg, err
+}
+
+// pollWithBackoff calls check until it reports done or fails, waiting a
+// little longer between each attempt, up to timeout in total.
+func pollWithBackoff(timeout time.Duration, check func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	interval := 250 * time.Millisecond
+
+	for {
+		done, err := check()
+		if err != nil || done {
+			return err
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			return fmt.Errorf("timed out after %s", timeout)
+		}
+		time.Sleep(interval)
+
+		interval *= 2
+		if interval > 5*time.Second {
+			interval = 5 * time.Second
+		}
+	}
+}
+
+func readCfHomeConfig() (cfHomeConfig, error) {
+	var config cfHomeConfig
+
+	data, err := ioutil.ReadFile(filepath.Join(os.Getenv("CF_HOME"), ".cf", "config.json"))
+	if err != nil {
+		return config, err
+	}
+
+	err = json.Unmarshal(data, &config)
+	if err == nil && config.Target == "" {
+		err = fmt.Errorf("no API endpoint targeted in $CF_HOME")
+	}
+	return config, err
+}
+
+// OAuthToken returns a freshly refreshed "bearer ..." token for the
+// currently targeted user.
+func OAuthToken() string {
+	token, err := oauthToken()
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	return token
+}
+
+func oauthToken() (string, error) {
+	session := cf.Cf("oauth-token").Wait(cf.CF_API_TIMEOUT)
+	if session.ExitCode() != 0 {
+		return "", fmt.Errorf("cf oauth-token exited with %d:\n%s", session.ExitCode(), session.Out.Contents())
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(session.Out.Contents())), "\n")
+	token := strings.TrimSpace(lines[len(lines)-1])
+	if !strings.HasPrefix(strings.ToLower(token), "bearer ") {
+		return "", fmt.Errorf("cf oauth-token printed no token:\n%s", session.Out.Contents())
+	}
+	return token, nil
+}
+
+func zipDirectory(directory string, destination io.Writer) error {
+	archive := zip.NewWriter(destination)
+
+	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == directory {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(directory, path)
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relativePath)
+
+		if info.IsDir() {
+			header.Name += "/"
+			_, err = archive.CreateHeader(header)
+			return err
+		}
+
+		header.Method = zip.Deflate
+		entry, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(entry, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
@@ -0,0 +1,138 @@
+package ccapi_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Package uploads", func() {
+	var originalCFHome, cfHome, appDir string
+
+	BeforeEach(func() {
+		var err error
+		cfHome, err = ioutil.TempDir("", "ccapi-cf-home")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(cfHome, ".cf"), 0755)).To(Succeed())
+
+		homeConfig, _ := json.Marshal(map[string]interface{}{"Target": fake.CloudController.URL(), "SSLDisabled": true})
+		Expect(ioutil.WriteFile(filepath.Join(cfHome, ".cf", "config.json"), homeConfig, 0644)).To(Succeed())
+
+		originalCFHome = os.Getenv("CF_HOME")
+		os.Setenv("CF_HOME", cfHome)
+
+		appDir, err = ioutil.TempDir("", "ccapi-app")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(appDir, "lib"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(appDir, "app.rb"), []byte("puts 'hi'"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(appDir, "lib", "helper.rb"), []byte("# helper"), 0644)).To(Succeed())
+
+		fake.CF.Handle("cf oauth-token", fakes.Output("Getting OAuth token...\nOK\n\nbearer fake-token\n"))
+	})
+
+	AfterEach(func() {
+		os.Setenv("CF_HOME", originalCFHome)
+		os.RemoveAll(cfHome)
+		os.RemoveAll(appDir)
+	})
+
+	Describe("UploadPackageBits", func() {
+		It("uploads the directory as a zip in a multipart request", func() {
+			var files map[string]string
+			fake.CloudController.RouteToHandler("POST", "/v3/packages/package-guid/upload", func(w http.ResponseWriter, req *http.Request) {
+				defer GinkgoRecover()
+				Expect(req.Header.Get("Authorization")).To(Equal("bearer fake-token"))
+
+				file, _, err := req.FormFile("bits")
+				Expect(err).NotTo(HaveOccurred())
+				data, err := ioutil.ReadAll(file)
+				Expect(err).NotTo(HaveOccurred())
+
+				archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+				Expect(err).NotTo(HaveOccurred())
+
+				files = map[string]string{}
+				for _, entry := range archive.File {
+					contents := ""
+					if !entry.FileInfo().IsDir() {
+						reader, err := entry.Open()
+						Expect(err).NotTo(HaveOccurred())
+						body, _ := ioutil.ReadAll(reader)
+						contents = string(body)
+					}
+					files[entry.Name] = contents
+				}
+
+				w.WriteHeader(201)
+				w.Write([]byte(`{"guid": "package-guid", "state": "PROCESSING_UPLOAD"}`))
+			})
+
+			Expect(ccapi.UploadPackageBits("package-guid", appDir)).To(Succeed())
+			Expect(files).To(Equal(map[string]string{
+				"app.rb":        "puts 'hi'",
+				"lib/":          "",
+				"lib/helper.rb": "# helper",
+			}))
+		})
+
+		It("reports Cloud Controller errors", func() {
+			fake.CloudController.RouteToError("POST", "/v3/packages/package-guid/upload", 422, "", "bits have already been uploaded")
+
+			err := ccapi.UploadPackageBits("package-guid", appDir)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("bits have already been uploaded"))
+		})
+
+		It("reports a missing token", func() {
+			fake.CF.Handle("cf oauth-token", fakes.Failure(1, "Not logged in."))
+
+			Expect(ccapi.UploadPackageBits("package-guid", appDir)).To(MatchError(ContainSubstring("cf oauth-token exited with 1")))
+		})
+	})
+
+	Describe("WaitForPackage", func() {
+		It("polls until the package is READY", func() {
+			states := []string{"PROCESSING_UPLOAD", "PROCESSING_UPLOAD", "READY"}
+			fake.CloudController.RouteToHandler("GET", "/v3/packages/package-guid", func(w http.ResponseWriter, req *http.Request) {
+				state := states[0]
+				if len(states) > 1 {
+					states = states[1:]
+				}
+				json.NewEncoder(w).Encode(map[string]string{"guid": "package-guid", "state": state})
+			})
+
+			pkg, err := ccapi.WaitForPackage("package-guid", 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pkg.State).To(Equal("READY"))
+		})
+
+		It("surfaces the error of a FAILED package", func() {
+			fake.CloudController.RouteToJSON("GET", "/v3/packages/package-guid", 200, map[string]string{
+				"guid":  "package-guid",
+				"state": "FAILED",
+				"error": "the zip file is invalid",
+			})
+
+			_, err := ccapi.WaitForPackage("package-guid", 10*time.Second)
+			Expect(err).To(MatchError("package package-guid failed: the zip file is invalid"))
+		})
+
+		It("gives up after the timeout", func() {
+			fake.CloudController.RouteToJSON("GET", "/v3/packages/package-guid", 200, map[string]string{"state": "PROCESSING_UPLOAD"})
+
+			_, err := ccapi.WaitForPackage("package-guid", 500*time.Millisecond)
+			Expect(err).To(MatchError(ContainSubstring("timed out")))
+		})
+	})
+})
@@ -0,0 +1,402 @@
+package ccapi
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+)
+
+type Metadata struct {
+	Guid      string `json:"guid"`
+	Url       string `json:"url"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type App struct {
+	Metadata Metadata `json:"metadata"`
+	Entity   struct {
+		Name              string `json:"name"`
+		SpaceGuid         string `json:"space_guid"`
+		StackGuid         string `json:"stack_guid"`
+		State             string `json:"state"`
+		PackageState      string `json:"package_state"`
+		Instances         int    `json:"instances"`
+		Memory            int    `json:"memory"`
+		DiskQuota         int    `json:"disk_quota"`
+		Buildpack         string `json:"buildpack"`
+		DetectedBuildpack string `json:"detected_buildpack"`
+		Command           string `json:"command"`
+	} `json:"entity"`
+}
+
+type AppInstanceStats struct {
+	State string `json:"state"`
+	Stats struct {
+		Name   string `json:"name"`
+		Host   string `json:"host"`
+		Port   int    `json:"port"`
+		Uptime int    `json:"uptime"`
+	} `json:"stats"`
+}
+
+// AppInstance is an entry of /v2/apps/:guid/instances, keyed by index.
+type AppInstance struct {
+	State string `json:"state"`
+
+	// Since is when the instance entered the state, in seconds since the
+	// epoch on the platform's clock.
+	Since float64 `json:"since"`
+}
+
+type Space struct {
+	Metadata Metadata `json:"metadata"`
+	Entity   struct {
+		Name             string `json:"name"`
+		OrganizationGuid string `json:"organization_guid"`
+	} `json:"entity"`
+}
+
+type Domain struct {
+	Metadata Metadata `json:"metadata"`
+	Entity   struct {
+		Name string `json:"name"`
+	} `json:"entity"`
+}
+
+type Route struct {
+	Metadata Metadata `json:"metadata"`
+	Entity   struct {
+		Host       string `json:"host"`
+		DomainGuid string `json:"domain_guid"`
+		SpaceGuid  string `json:"space_guid"`
+	} `json:"entity"`
+}
+
+type Buildpack struct {
+	Metadata Metadata `json:"metadata"`
+	Entity   struct {
+		Name     string `json:"name"`
+		Position int    `json:"position"`
+		Enabled  bool   `json:"enabled"`
+		Locked   bool   `json:"locked"`
+		Filename string `json:"filename"`
+	} `json:"entity"`
+}
+
+type Service struct {
+	Metadata Metadata `json:"metadata"`
+	Entity   struct {
+		Label        string        `json:"label"`
+		Description  string        `json:"description"`
+		Active       bool          `json:"active"`
+		ServicePlans []ServicePlan `json:"service_plans"`
+	} `json:"entity"`
+}
+
+type ServicePlan struct {
+	Metadata Metadata `json:"metadata"`
+	Entity   struct {
+		Name        string `json:"name"`
+		Free        bool   `json:"free"`
+		Public      bool   `json:"public"`
+		UniqueId    string `json:"unique_id"`
+		ServiceGuid string `json:"service_guid"`
+	} `json:"entity"`
+}
+
+type LastOperation struct {
+	Type        string `json:"type"`
+	State       string `json:"state"`
+	Description string `json:"description"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// Finished reports whether the operation is no longer in progress, having
+// succeeded or failed.
+func (o LastOperation) Finished() bool {
+	return o.State != "in progress"
+}
+
+func (o LastOperation) String() string {
+	description := ""
+	if o.Description != "" {
+		description = fmt.Sprintf(" (%s)", o.Description)
+	}
+	return fmt.Sprintf("%s %s%s, updated at %s", o.Type, o.State, description, o.UpdatedAt)
+}
+
+type ServiceInstance struct {
+	Metadata Metadata `json:"metadata"`
+	Entity   struct {
+		Name            string        `json:"name"`
+		SpaceGuid       string        `json:"space_guid"`
+		ServicePlanGuid string        `json:"service_plan_guid"`
+		DashboardUrl    string        `json:"dashboard_url"`
+		LastOperation   LastOperation `json:"last_operation"`
+	} `json:"entity"`
+}
+
+type ServiceBinding struct {
+	Metadata Metadata `json:"metadata"`
+	Entity   struct {
+		AppGuid             string                 `json:"app_guid"`
+		ServiceInstanceGuid string                 `json:"service_instance_guid"`
+		Credentials         map[string]interface{} `json:"credentials"`
+	} `json:"entity"`
+}
+
+type AppUsageEvent struct {
+	Metadata Metadata `json:"metadata"`
+	Entity   struct {
+		AppGuid       string `json:"app_guid"`
+		AppName       string `json:"app_name"`
+		State         string `json:"state"`
+		SpaceGuid     string `json:"space_guid"`
+		BuildpackName string `json:"buildpack_name"`
+		BuildpackGuid string `json:"buildpack_guid"`
+	} `json:"entity"`
+}
+
+type Event struct {
+	Metadata Metadata `json:"metadata"`
+	Entity   struct {
+		Type      string                 `json:"type"`
+		Actor     string                 `json:"actor"`
+		ActorType string                 `json:"actor_type"`
+		ActorName string                 `json:"actor_name"`
+		Actee     string                 `json:"actee"`
+		ActeeType string                 `json:"actee_type"`
+		ActeeName string                 `json:"actee_name"`
+		Timestamp string                 `json:"timestamp"`
+		Metadata  map[string]interface{} `json:"metadata"`
+	} `json:"entity"`
+}
+
+// CrashEvent is an app.crash audit event with its metadata decoded.
+type CrashEvent struct {
+	Metadata Metadata `json:"metadata"`
+	Entity   struct {
+		Type      string `json:"type"`
+		Actee     string `json:"actee"`
+		Timestamp string `json:"timestamp"`
+		Metadata  struct {
+			Instance        string `json:"instance"`
+			Index           int    `json:"index"`
+			ExitStatus      int    `json:"exit_status"`
+			ExitDescription string `json:"exit_description"`
+			Reason          string `json:"reason"`
+		} `json:"metadata"`
+	} `json:"entity"`
+}
+
+func byName(name string) string {
+	return "q=name:" + url.QueryEscape(name)
+}
+
+func expectOne(kind, name string, found int) {
+	ExpectWithOffset(2, found).To(Equal(1), fmt.Sprintf("expected exactly one %s named '%s', found %d", kind, name, found))
+}
+
+func FindApp(name string) App {
+	var apps []App
+	ListAll("/v2/apps?"+byName(name), &apps)
+	expectOne("app", name, len(apps))
+	return apps[0]
+}
+
+func GetApp(guid string) App {
+	var app App
+	Get("/v2/apps/"+guid, &app)
+	return app
+}
+
+func GetAppStats(guid string) map[string]AppInstanceStats {
+	stats := map[string]AppInstanceStats{}
+	Get(fmt.Sprintf("/v2/apps/%s/stats", guid), &stats)
+	return stats
+}
+
+func GetAppInstances(guid string) map[string]AppInstance {
+	instances := map[string]AppInstance{}
+	Get(fmt.Sprintf("/v2/apps/%s/instances", guid), &instances)
+	return instances
+}
+
+// WaitForInstance polls the app's instances until the one at index satisfies
+// ready, e.g. is RUNNING again since a crash. Errors from the Cloud
+// Controller, which it returns while instances are starting, are retried.
+func WaitForInstance(appGuid string, index int, timeout time.Duration, ready func(AppInstance) bool) (AppInstance, error) {
+	var instance AppInstance
+	err := pollWithBackoff(timeout, func() (bool, error) {
+		instances := map[string]AppInstance{}
+		err := Request("GET", fmt.Sprintf("/v2/apps/%s/instances", appGuid), &instances)
+		if _, ok := err.(*Error); ok {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		var found bool
+		instance, found = instances[strconv.Itoa(index)]
+		return found && ready(instance), nil
+	})
+	if err != nil {
+		return instance, fmt.Errorf("waiting for instance %d of app %s, last seen as %+v: %s", index, appGuid, instance, err)
+	}
+	return instance, nil
+}
+
+func FindSpace(name string) Space {
+	var spaces []Space
+	ListAll("/v2/spaces?"+byName(name), &spaces)
+	expectOne("space", name, len(spaces))
+	return spaces[0]
+}
+
+func FindBuildpack(name string) Buildpack {
+	var buildpacks []Buildpack
+	ListAll("/v2/buildpacks?"+byName(name), &buildpacks)
+	expectOne("buildpack", name, len(buildpacks))
+	return buildpacks[0]
+}
+
+func FindSharedDomain(name string) Domain {
+	var domains []Domain
+	ListAll("/v2/shared_domains?"+byName(name), &domains)
+	expectOne("shared domain", name, len(domains))
+	return domains[0]
+}
+
+// CreateRoute creates host.domain in the space, e.g. to map to a v3 app.
+func CreateRoute(host, domainGuid, spaceGuid string) Route {
+	var route Route
+	Post("/v2/routes", &route, jsonBody(map[string]interface{}{
+		"host":        host,
+		"domain_guid": domainGuid,
+		"space_guid":  spaceGuid,
+	}))
+	cleanup.Current().Track("route "+host, func() error {
+		return deleteIfExists("/v2/routes/" + route.Metadata.Guid)
+	})
+	return route
+}
+
+func DeleteRoute(guid string) {
+	Delete("/v2/routes/" + guid)
+}
+
+// FindService looks up a service by label with its plans inlined.
+func FindService(label string) Service {
+	var services []Service
+	ListAll("/v2/services?inline-relations-depth=1&q=label:"+url.QueryEscape(label), &services)
+	expectOne("service", label, len(services))
+	return services[0]
+}
+
+func FindServiceInstance(name string) ServiceInstance {
+	var instances []ServiceInstance
+	ListAll("/v2/service_instances?"+byName(name), &instances)
+	expectOne("service instance", name, len(instances))
+	return instances[0]
+}
+
+func FindSpaceServiceInstance(spaceGuid, name string) ServiceInstance {
+	var instances []ServiceInstance
+	ListAll(fmt.Sprintf("/v2/spaces/%s/service_instances?%s", spaceGuid, byName(name)), &instances)
+	expectOne("service instance", name, len(instances))
+	return instances[0]
+}
+
+func GetServiceInstance(guid string) ServiceInstance {
+	var instance ServiceInstance
+	Get("/v2/service_instances/"+guid, &instance)
+	return instance
+}
+
+// WaitForLastOperation polls the service instance until its last operation
+// satisfies done, e.g. LastOperation.Finished, and logs every change of the
+// operation to the GinkgoWriter as it goes.
+func WaitForLastOperation(instanceGuid string, timeout time.Duration, done func(LastOperation) bool) (LastOperation, error) {
+	var operation LastOperation
+	seen := false
+	err := pollWithBackoff(timeout, func() (bool, error) {
+		var instance ServiceInstance
+		err := Request("GET", "/v2/service_instances/"+instanceGuid, &instance)
+		if err != nil {
+			return false, err
+		}
+
+		current := instance.Entity.LastOperation
+		if !seen || current != operation {
+			fmt.Fprintf(GinkgoWriter, "service instance %s: %s\n", instanceGuid, current)
+		}
+		operation, seen = current, true
+		return done(operation), nil
+	})
+	if err != nil {
+		return operation, fmt.Errorf("waiting for the last operation of service instance %s, last seen as %s: %s", instanceGuid, operation, err)
+	}
+	return operation, nil
+}
+
+// FindServiceBinding looks up the binding of the service instance to the app.
+func FindServiceBinding(appGuid, instanceGuid string) ServiceBinding {
+	var bindings []ServiceBinding
+	ListAll("/v2/service_bindings?q=app_guid:"+url.QueryEscape(appGuid)+"&q=service_instance_guid:"+url.QueryEscape(instanceGuid), &bindings)
+	expectOne("service binding", appGuid+"/"+instanceGuid, len(bindings))
+	return bindings[0]
+}
+
+// AppEvents returns the audit events recorded for an app, oldest first.
+func AppEvents(appGuid string) []Event {
+	var events []Event
+	ListAll("/v2/events?q=actee:"+url.QueryEscape(appGuid), &events)
+	return events
+}
+
+// AppCrashEvents returns the app's app.crash events, oldest first.
+func AppCrashEvents(appGuid string) []CrashEvent {
+	var events []CrashEvent
+	ListAll("/v2/events?q=actee:"+url.QueryEscape(appGuid)+"&q=type:app.crash", &events)
+	return events
+}
+
+// LastAppUsageEvent walks the app usage events newest first and returns the
+// most recent one for the app in the given state. Events from well before the
+// app was created are not inspected, so a missing event doesn't page through
+// the whole history.
+func LastAppUsageEvent(app App, state string) (AppUsageEvent, bool) {
+	createdAt, err := time.Parse(time.RFC3339, app.Metadata.CreatedAt)
+	hasCreatedAt := err == nil
+	notBefore := createdAt.Add(-time.Minute)
+
+	it := NewIterator("/v2/app_usage_events?order-direction=desc&results-per-page=100")
+	for it.Next() {
+		var event AppUsageEvent
+		if it.Decode(&event) != nil {
+			break
+		}
+
+		if event.Entity.AppGuid == app.Metadata.Guid && event.Entity.State == state {
+			return event, true
+		}
+
+		if hasCreatedAt {
+			eventCreatedAt, err := time.Parse(time.RFC3339, event.Metadata.CreatedAt)
+			if err == nil && eventCreatedAt.Before(notBefore) {
+				break
+			}
+		}
+	}
+	ExpectWithOffset(1, it.Err()).NotTo(HaveOccurred())
+
+	return AppUsageEvent{}, false
+}
@@ -0,0 +1,311 @@
+package ccapi_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/ghttp"
+)
+
+func appResource(guid, name string) map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]string{"guid": guid},
+		"entity":   map[string]string{"name": name},
+	}
+}
+
+var _ = Describe("v2 resources", func() {
+	Describe("FindApp", func() {
+		It("returns the single matching app", func() {
+			fake.CloudController.RouteToPages("/v2/apps", []interface{}{appResource("app-guid", "dora")})
+
+			Expect(ccapi.FindApp("dora").Metadata.Guid).To(Equal("app-guid"))
+			Expect(fake.CloudController.ReceivedRequests()[0].URL.RawQuery).To(Equal("q=name:dora"))
+		})
+
+		It("fails with a useful message instead of indexing an empty result", func() {
+			fake.CloudController.RouteToPages("/v2/apps", []interface{}{})
+
+			failures := InterceptGomegaFailures(func() {
+				defer func() { recover() }()
+				ccapi.FindApp("dora")
+			})
+			Expect(failures).To(HaveLen(1))
+			Expect(failures[0]).To(ContainSubstring("expected exactly one app named 'dora', found 0"))
+		})
+	})
+
+	Describe("AppEvents", func() {
+		It("filters events by the app guid", func() {
+			fake.CloudController.RouteToPages("/v2/events", []interface{}{
+				map[string]interface{}{"entity": map[string]string{"type": "audit.app.create", "actee": "app-guid"}},
+			})
+
+			events := ccapi.AppEvents("app-guid")
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Entity.Type).To(Equal("audit.app.create"))
+			Expect(fake.CloudController.ReceivedRequests()[0].URL.RawQuery).To(Equal("q=actee:app-guid"))
+		})
+	})
+
+	Describe("LastAppUsageEvent", func() {
+		var app ccapi.App
+
+		usageEvent := func(appGuid, state, createdAt string) map[string]interface{} {
+			return map[string]interface{}{
+				"metadata": map[string]string{"created_at": createdAt},
+				"entity":   map[string]string{"app_guid": appGuid, "state": state},
+			}
+		}
+
+		BeforeEach(func() {
+			app.Metadata.Guid = "app-guid"
+			app.Metadata.CreatedAt = "2015-06-01T12:00:00Z"
+		})
+
+		It("finds the app's event beyond the first page", func() {
+			fake.CloudController.RouteToPages("/v2/app_usage_events",
+				[]interface{}{usageEvent("other-guid", "STARTED", "2015-06-01T12:05:00Z")},
+				[]interface{}{usageEvent("app-guid", "STOPPED", "2015-06-01T12:04:00Z")},
+				[]interface{}{usageEvent("app-guid", "STARTED", "2015-06-01T12:01:00Z")},
+			)
+
+			event, found := ccapi.LastAppUsageEvent(app, "STARTED")
+			Expect(found).To(BeTrue())
+			Expect(event.Entity.AppGuid).To(Equal("app-guid"))
+			Expect(fake.CloudController.ReceivedRequests()).To(HaveLen(3))
+		})
+
+		It("ignores events of other apps with the same name", func() {
+			fake.CloudController.RouteToPages("/v2/app_usage_events", []interface{}{
+				map[string]interface{}{
+					"metadata": map[string]string{"created_at": "2015-06-01T12:05:00Z"},
+					"entity":   map[string]string{"app_guid": "other-guid", "app_name": "dora", "state": "STARTED"},
+				},
+			})
+			app.Entity.Name = "dora"
+
+			_, found := ccapi.LastAppUsageEvent(app, "STARTED")
+			Expect(found).To(BeFalse())
+		})
+
+		It("stops paging once the events predate the app", func() {
+			fake.CloudController.RouteToPages("/v2/app_usage_events",
+				[]interface{}{usageEvent("other-guid", "STARTED", "2015-06-01T10:00:00Z")},
+				[]interface{}{usageEvent("app-guid", "STARTED", "2015-06-01T09:00:00Z")},
+			)
+
+			_, found := ccapi.LastAppUsageEvent(app, "STARTED")
+			Expect(found).To(BeFalse())
+			Expect(fake.CloudController.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Describe("GetAppStats", func() {
+		It("decodes the stats of every instance", func() {
+			fake.CloudController.RouteToJSON("GET", "/v2/apps/app-guid/stats", 200, map[string]interface{}{
+				"0": map[string]interface{}{
+					"state": "RUNNING",
+					"stats": map[string]interface{}{"host": "10.0.0.1", "port": 61001},
+				},
+			})
+
+			stats := ccapi.GetAppStats("app-guid")
+			Expect(stats["0"].State).To(Equal("RUNNING"))
+			Expect(stats["0"].Stats.Host).To(Equal("10.0.0.1"))
+			Expect(stats["0"].Stats.Port).To(Equal(61001))
+		})
+	})
+
+	Describe("WaitForInstance", func() {
+		It("polls through errors until the instance is ready", func() {
+			responses := []http.HandlerFunc{
+				ghttp.RespondWithJSONEncoded(400, map[string]interface{}{
+					"code": 220001, "error_code": "CF-InstancesError", "description": "Instances error",
+				}),
+				ghttp.RespondWithJSONEncoded(200, map[string]interface{}{
+					"0": map[string]interface{}{"state": "RUNNING", "since": 100.0},
+					"1": map[string]interface{}{"state": "DOWN", "since": 200.0},
+				}),
+				ghttp.RespondWithJSONEncoded(200, map[string]interface{}{
+					"0": map[string]interface{}{"state": "RUNNING", "since": 100.0},
+					"1": map[string]interface{}{"state": "RUNNING", "since": 210.5},
+				}),
+			}
+			fake.CloudController.RouteToHandler("GET", "/v2/apps/app-guid/instances", func(w http.ResponseWriter, req *http.Request) {
+				response := responses[0]
+				if len(responses) > 1 {
+					responses = responses[1:]
+				}
+				response(w, req)
+			})
+
+			instance, err := ccapi.WaitForInstance("app-guid", 1, 5*time.Second, func(instance ccapi.AppInstance) bool {
+				return instance.State == "RUNNING" && instance.Since > 150
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance.Since).To(Equal(210.5))
+			Expect(fake.CloudController.ReceivedRequests()).To(HaveLen(3))
+		})
+
+		It("times out with the instance's last state", func() {
+			fake.CloudController.RouteToJSON("GET", "/v2/apps/app-guid/instances", 200, map[string]interface{}{
+				"0": map[string]interface{}{"state": "CRASHED", "since": 100.0},
+			})
+
+			_, err := ccapi.WaitForInstance("app-guid", 0, 300*time.Millisecond, func(instance ccapi.AppInstance) bool {
+				return instance.State == "RUNNING"
+			})
+			Expect(err).To(MatchError(ContainSubstring("waiting for instance 0 of app app-guid")))
+			Expect(err).To(MatchError(ContainSubstring("CRASHED")))
+		})
+	})
+
+	Describe("WaitForLastOperation", func() {
+		var log *gbytes.Buffer
+		var originalWriter io.Writer
+
+		lastOperation := func(state, description string) http.HandlerFunc {
+			return ghttp.RespondWithJSONEncoded(200, map[string]interface{}{
+				"metadata": map[string]string{"guid": "instance-guid"},
+				"entity": map[string]interface{}{
+					"last_operation": map[string]string{
+						"type":        "create",
+						"state":       state,
+						"description": description,
+						"updated_at":  "2015-06-01T12:00:00Z",
+					},
+				},
+			})
+		}
+
+		routeTo := func(responses ...http.HandlerFunc) {
+			fake.CloudController.RouteToHandler("GET", "/v2/service_instances/instance-guid", func(w http.ResponseWriter, req *http.Request) {
+				response := responses[0]
+				if len(responses) > 1 {
+					responses = responses[1:]
+				}
+				response(w, req)
+			})
+		}
+
+		BeforeEach(func() {
+			log = gbytes.NewBuffer()
+			originalWriter = GinkgoWriter
+			GinkgoWriter = log
+		})
+
+		AfterEach(func() {
+			GinkgoWriter = originalWriter
+		})
+
+		It("polls the instance until the operation is done and logs each change", func() {
+			routeTo(
+				lastOperation("in progress", "started"),
+				lastOperation("in progress", "started"),
+				lastOperation("in progress", "5started"),
+				lastOperation("succeeded", "10started"),
+			)
+
+			operation, err := ccapi.WaitForLastOperation("instance-guid", 5*time.Second, ccapi.LastOperation.Finished)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(operation).To(Equal(ccapi.LastOperation{Type: "create", State: "succeeded", Description: "10started", UpdatedAt: "2015-06-01T12:00:00Z"}))
+			Expect(fake.CloudController.ReceivedRequests()).To(HaveLen(4))
+
+			Expect(log).To(gbytes.Say(`service instance instance-guid: create in progress \(started\), updated at 2015-06-01T12:00:00Z\n`))
+			Expect(log).To(gbytes.Say(`service instance instance-guid: create in progress \(5started\)`))
+			Expect(log).To(gbytes.Say(`service instance instance-guid: create succeeded \(10started\)`))
+			Expect(strings.Count(string(log.Contents()), "service instance instance-guid:")).To(Equal(3))
+		})
+
+		It("times out with the operation's last state", func() {
+			routeTo(lastOperation("in progress", "still provisioning"))
+
+			_, err := ccapi.WaitForLastOperation("instance-guid", 300*time.Millisecond, ccapi.LastOperation.Finished)
+			Expect(err).To(MatchError(ContainSubstring("waiting for the last operation of service instance instance-guid")))
+			Expect(err).To(MatchError(ContainSubstring("create in progress (still provisioning)")))
+		})
+
+		It("fails when the Cloud Controller does", func() {
+			fake.CloudController.RouteToError("GET", "/v2/service_instances/instance-guid", 404, "CF-ServiceInstanceNotFound", "The service instance could not be found")
+
+			_, err := ccapi.WaitForLastOperation("instance-guid", 5*time.Second, ccapi.LastOperation.Finished)
+			Expect(err).To(MatchError(ContainSubstring("could not be found")))
+		})
+	})
+
+	Describe("AppCrashEvents", func() {
+		It("decodes the crash details of the app's crash events", func() {
+			fake.CloudController.RouteToPages("/v2/events", []interface{}{
+				map[string]interface{}{"entity": map[string]interface{}{
+					"type":  "app.crash",
+					"actee": "app-guid",
+					"metadata": map[string]interface{}{
+						"instance":         "instance-guid",
+						"index":            1,
+						"exit_status":      137,
+						"exit_description": "Exited with status 137",
+						"reason":           "CRASHED",
+					},
+				}},
+			})
+
+			events := ccapi.AppCrashEvents("app-guid")
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Entity.Metadata.Index).To(Equal(1))
+			Expect(events[0].Entity.Metadata.ExitStatus).To(Equal(137))
+			Expect(events[0].Entity.Metadata.Reason).To(Equal("CRASHED"))
+			Expect(fake.CloudController.ReceivedRequests()[0].URL.RawQuery).To(Equal("q=actee:app-guid&q=type:app.crash"))
+		})
+	})
+
+	Describe("FindServiceBinding", func() {
+		It("finds the binding by app and service instance", func() {
+			fake.CloudController.RouteToPages("/v2/service_bindings", []interface{}{
+				map[string]interface{}{
+					"metadata": map[string]string{"guid": "binding-guid"},
+					"entity": map[string]interface{}{
+						"app_guid":              "app-guid",
+						"service_instance_guid": "instance-guid",
+						"credentials":           map[string]interface{}{"username": "fake-user"},
+					},
+				},
+			})
+
+			binding := ccapi.FindServiceBinding("app-guid", "instance-guid")
+			Expect(binding.Metadata.Guid).To(Equal("binding-guid"))
+			Expect(binding.Entity.Credentials).To(HaveKeyWithValue("username", "fake-user"))
+			Expect(fake.CloudController.ReceivedRequests()[0].URL.RawQuery).To(Equal("q=app_guid:app-guid&q=service_instance_guid:instance-guid"))
+		})
+	})
+
+	Describe("CreateRoute", func() {
+		It("creates the route on a shared domain", func() {
+			fake.CloudController.RouteToPages("/v2/shared_domains", []interface{}{
+				map[string]interface{}{
+					"metadata": map[string]string{"guid": "domain-guid"},
+					"entity":   map[string]string{"name": "example.com"},
+				},
+			})
+			fake.CloudController.RouteToHandler("POST", "/v2/routes", ghttp.CombineHandlers(
+				verifyBody(`{"host": "dora", "domain_guid": "domain-guid", "space_guid": "space-guid"}`),
+				ghttp.RespondWithJSONEncoded(201, map[string]interface{}{
+					"metadata": map[string]string{"guid": "route-guid"},
+					"entity":   map[string]string{"host": "dora", "domain_guid": "domain-guid"},
+				}),
+			))
+
+			domain := ccapi.FindSharedDomain("example.com")
+			route := ccapi.CreateRoute("dora", domain.Metadata.Guid, "space-guid")
+			Expect(route.Metadata.Guid).To(Equal("route-guid"))
+			Expect(fake.CloudController.ReceivedRequests()[0].URL.RawQuery).To(Equal("q=name:example.com"))
+		})
+	})
+})
@@ -0,0 +1,171 @@
+package ccapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+)
+
+type Link struct {
+	Href   string `json:"href"`
+	Method string `json:"method,omitempty"`
+}
+
+type V3App struct {
+	Guid         string          `json:"guid"`
+	Name         string          `json:"name"`
+	DesiredState string          `json:"desired_state"`
+	CreatedAt    string          `json:"created_at"`
+	UpdatedAt    string          `json:"updated_at"`
+	Links        map[string]Link `json:"_links"`
+}
+
+type Package struct {
+	Guid      string          `json:"guid"`
+	Type      string          `json:"type"`
+	State     string          `json:"state"`
+	Error     string          `json:"error"`
+	Hash      string          `json:"hash"`
+	Url       string          `json:"url"`
+	CreatedAt string          `json:"created_at"`
+	Links     map[string]Link `json:"_links"`
+}
+
+type Droplet struct {
+	Guid            string          `json:"guid"`
+	State           string          `json:"state"`
+	Error           string          `json:"error"`
+	Hash            string          `json:"hash"`
+	BuildpackGitUrl string          `json:"buildpack_git_url"`
+	CreatedAt       string          `json:"created_at"`
+	Links           map[string]Link `json:"_links"`
+}
+
+func jsonBody(attributes map[string]interface{}) string {
+	body, err := json.Marshal(attributes)
+	if err != nil {
+		panic(err)
+	}
+	return string(body)
+}
+
+func CreateV3App(name, spaceGuid string) V3App {
+	var app V3App
+	Post("/v3/apps", &app, jsonBody(map[string]interface{}{
+		"name":       name,
+		"space_guid": spaceGuid,
+	}))
+	cleanup.Current().Track("v3 app "+name, func() error {
+		return deleteIfExists("/v3/apps/" + app.Guid)
+	})
+	return app
+}
+
+func GetV3App(guid string) V3App {
+	var app V3App
+	Get("/v3/apps/"+guid, &app)
+	return app
+}
+
+func DeleteV3App(guid string) {
+	Delete("/v3/apps/" + guid)
+}
+
+func CreatePackage(appGuid, packageType string) Package {
+	var pkg Package
+	Post(fmt.Sprintf("/v3/apps/%s/packages", appGuid), &pkg, jsonBody(map[string]interface{}{
+		"type": packageType,
+	}))
+	return pkg
+}
+
+func GetPackage(guid string) Package {
+	var pkg Package
+	Get("/v3/packages/"+guid, &pkg)
+	return pkg
+}
+
+// CreateDroplet stages a package. stagingRequest holds the staging options,
+// e.g. buildpack_guid or buildpack_git_url.
+func CreateDroplet(packageGuid string, stagingRequest map[string]interface{}) Droplet {
+	var droplet Droplet
+	Post(fmt.Sprintf("/v3/packages/%s/droplets", packageGuid), &droplet, jsonBody(stagingRequest))
+	return droplet
+}
+
+func GetDroplet(guid string) Droplet {
+	var droplet Droplet
+	Get("/v3/droplets/"+guid, &droplet)
+	return droplet
+}
+
+// StagePackage stages a package with the given staging options and waits up
+// to timeout for the droplet to be STAGED. Pass an empty stagingRequest to
+// let the platform detect the buildpack.
+func StagePackage(packageGuid string, stagingRequest map[string]interface{}, timeout time.Duration) Droplet {
+	droplet := CreateDroplet(packageGuid, stagingRequest)
+
+	droplet, err := WaitForDroplet(droplet.Guid, timeout)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	return droplet
+}
+
+// WaitForDroplet polls a droplet until it is STAGED, returning an error with
+// the droplet's error message if it FAILED.
+func WaitForDroplet(guid string, timeout time.Duration) (Droplet, error) {
+	var droplet Droplet
+	err := pollWithBackoff(timeout, func() (bool, error) {
+		err := Request("GET", "/v3/droplets/"+guid, &droplet)
+		if err != nil {
+			return false, err
+		}
+
+		switch droplet.State {
+		case "STAGED":
+			return true, nil
+		case "FAILED":
+			return false, fmt.Errorf("droplet %s failed to stage: %s", guid, droplet.Error)
+		}
+		return false, nil
+	})
+	return droplet, err
+}
+
+// AssignDroplet makes the droplet the one the app runs when it is started.
+func AssignDroplet(appGuid, dropletGuid string) V3App {
+	var app V3App
+	Put(fmt.Sprintf("/v3/apps/%s/current_droplet", appGuid), &app, jsonBody(map[string]interface{}{
+		"desired_droplet_guid": dropletGuid,
+	}))
+	return app
+}
+
+func StartV3App(guid string) V3App {
+	var app V3App
+	Put(fmt.Sprintf("/v3/apps/%s/start", guid), &app)
+	return app
+}
+
+func StopV3App(guid string) V3App {
+	var app V3App
+	Put(fmt.Sprintf("/v3/apps/%s/stop", guid), &app)
+	return app
+}
+
+// MapV3Route sends the route's traffic to the app. Routes are still created
+// through v2, see CreateRoute.
+func MapV3Route(appGuid, routeGuid string) {
+	Put(fmt.Sprintf("/v3/apps/%s/routes", appGuid), nil, jsonBody(map[string]interface{}{
+		"route_guid": routeGuid,
+	}))
+}
+
+func UnmapV3Route(appGuid, routeGuid string) {
+	ExpectWithOffset(1, Request("DELETE", fmt.Sprintf("/v3/apps/%s/routes", appGuid), nil, jsonBody(map[string]interface{}{
+		"route_guid": routeGuid,
+	}))).To(Succeed())
+}
@@ -0,0 +1,105 @@
+package ccapi_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+func verifyBody(expectedJSON string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(MatchJSON(expectedJSON))
+	}
+}
+
+var _ = Describe("v3 droplets", func() {
+	Describe("WaitForDroplet", func() {
+		It("polls until the droplet is staged", func() {
+			fake.CloudController.AppendHandlers(
+				ghttp.RespondWithJSONEncoded(200, map[string]string{"guid": "droplet-guid", "state": "PENDING"}),
+				ghttp.RespondWithJSONEncoded(200, map[string]string{"guid": "droplet-guid", "state": "STAGING"}),
+				ghttp.RespondWithJSONEncoded(200, map[string]string{"guid": "droplet-guid", "state": "STAGED"}),
+			)
+
+			droplet, err := ccapi.WaitForDroplet("droplet-guid", 5*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(droplet.State).To(Equal("STAGED"))
+			Expect(fake.CloudController.ReceivedRequests()).To(HaveLen(3))
+			Expect(fake.CloudController.ReceivedRequests()[0].URL.Path).To(Equal("/v3/droplets/droplet-guid"))
+		})
+
+		It("returns the staging error when the droplet failed", func() {
+			fake.CloudController.RouteToJSON("GET", "/v3/droplets/droplet-guid", 200, map[string]string{
+				"guid":  "droplet-guid",
+				"state": "FAILED",
+				"error": "NoAppDetectedError",
+			})
+
+			_, err := ccapi.WaitForDroplet("droplet-guid", 5*time.Second)
+			Expect(err).To(MatchError("droplet droplet-guid failed to stage: NoAppDetectedError"))
+		})
+
+		It("gives up after the timeout", func() {
+			fake.CloudController.RouteToJSON("GET", "/v3/droplets/droplet-guid", 200, map[string]string{"state": "STAGING"})
+
+			_, err := ccapi.WaitForDroplet("droplet-guid", 100*time.Millisecond)
+			Expect(err).To(MatchError("timed out after 100ms"))
+		})
+	})
+
+	Describe("StagePackage", func() {
+		It("creates a droplet from the package and waits for it", func() {
+			fake.CloudController.RouteToHandler("POST", "/v3/packages/package-guid/droplets", ghttp.CombineHandlers(
+				verifyBody(`{"buildpack_guid": "buildpack-guid"}`),
+				ghttp.RespondWithJSONEncoded(201, map[string]string{"guid": "droplet-guid", "state": "PENDING"}),
+			))
+			fake.CloudController.RouteToJSON("GET", "/v3/droplets/droplet-guid", 200, map[string]string{"guid": "droplet-guid", "state": "STAGED"})
+
+			droplet := ccapi.StagePackage("package-guid", map[string]interface{}{"buildpack_guid": "buildpack-guid"}, 5*time.Second)
+			Expect(droplet.Guid).To(Equal("droplet-guid"))
+			Expect(droplet.State).To(Equal("STAGED"))
+		})
+	})
+
+	Describe("running an app", func() {
+		It("assigns the droplet as the app's current droplet", func() {
+			fake.CloudController.RouteToHandler("PUT", "/v3/apps/app-guid/current_droplet", ghttp.CombineHandlers(
+				verifyBody(`{"desired_droplet_guid": "droplet-guid"}`),
+				ghttp.RespondWithJSONEncoded(200, map[string]string{"guid": "app-guid", "desired_state": "STOPPED"}),
+			))
+
+			Expect(ccapi.AssignDroplet("app-guid", "droplet-guid").Guid).To(Equal("app-guid"))
+		})
+
+		It("starts and stops the app", func() {
+			fake.CloudController.RouteToJSON("PUT", "/v3/apps/app-guid/start", 200, map[string]string{"guid": "app-guid", "desired_state": "STARTED"})
+			fake.CloudController.RouteToJSON("PUT", "/v3/apps/app-guid/stop", 200, map[string]string{"guid": "app-guid", "desired_state": "STOPPED"})
+
+			Expect(ccapi.StartV3App("app-guid").DesiredState).To(Equal("STARTED"))
+			Expect(ccapi.StopV3App("app-guid").DesiredState).To(Equal("STOPPED"))
+		})
+
+		It("maps and unmaps routes", func() {
+			fake.CloudController.RouteToHandler("PUT", "/v3/apps/app-guid/routes", ghttp.CombineHandlers(
+				verifyBody(`{"route_guid": "route-guid"}`),
+				ghttp.RespondWith(204, ""),
+			))
+			fake.CloudController.RouteToHandler("DELETE", "/v3/apps/app-guid/routes", ghttp.CombineHandlers(
+				verifyBody(`{"route_guid": "route-guid"}`),
+				ghttp.RespondWith(204, ""),
+			))
+
+			ccapi.MapV3Route("app-guid", "route-guid")
+			ccapi.UnmapV3Route("app-guid", "route-guid")
+			Expect(fake.CloudController.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+})
@@ -0,0 +1,287 @@
+// Package cleanup records the resources a spec creates and removes them after
+// the spec, whether it passed or not.
+//
+// Suites call Install once while building the spec tree. Helpers that create
+// resources, such as push.Push, record them in Current(); specs record what
+// they create with cf directly:
+//
+//	Expect(cf.Cf("create-buildpack", name, zip, "999").Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+//	cleanup.Current().Buildpack(name)
+//
+// Resources are removed newest first, so a binding recorded after the
+// resources it binds is undone before they are deleted.
+package cleanup
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+)
+
+const DefaultTimeout = time.Minute
+
+// Tracker holds the resources recorded during a spec.
+type Tracker struct {
+	// Timeout applies to each cf command run to remove a resource.
+	Timeout time.Duration
+
+	adminContext cf.UserContext
+	enabled      bool
+
+	lock      sync.Mutex
+	resources []resource
+}
+
+type resource struct {
+	description string
+	asAdmin     bool
+	remove      func() error
+}
+
+// Leak is a resource that could not be removed.
+type Leak struct {
+	Description string
+	Err         error
+}
+
+// Leaks is returned by Cleanup when resources could not be removed.
+type Leaks []Leak
+
+func (l Leaks) Error() string {
+	lines := []string{fmt.Sprintf("failed to clean up %d resource(s):", len(l))}
+	for _, leak := range l {
+		lines = append(lines, fmt.Sprintf("  %s: %s", leak.Description, leak.Err))
+	}
+	return strings.Join(lines, "\n")
+}
+
+var current = &Tracker{}
+
+// NewTracker returns a tracker that removes admin-only resources, such as
+// buildpacks and security groups, as the given admin user.
+func NewTracker(adminContext cf.UserContext) *Tracker {
+	return &Tracker{
+		Timeout:      DefaultTimeout,
+		adminContext: adminContext,
+		enabled:      true,
+	}
+}
+
+// Install makes a new tracker current and adds a top-level AfterEach that
+// cleans up after every spec, failing the spec with whatever could not be
+// removed. Call it from the suite's Test function before RunSpecs.
+func Install(adminContext cf.UserContext) *Tracker {
+	tracker := NewTracker(adminContext)
+	current = tracker
+
+	AfterEach(func() {
+		Expect(tracker.Cleanup()).To(Succeed())
+	})
+
+	return tracker
+}
+
+// Current returns the installed tracker. Until Install is called it ignores
+// everything recorded in it, so helpers can record unconditionally.
+func Current() *Tracker {
+	return current
+}
+
+// Track records a resource that remove deletes as the current user.
+func (t *Tracker) Track(description string, remove func() error) {
+	t.add(resource{description: description, remove: remove})
+}
+
+// TrackAsAdmin records a resource that remove deletes as the admin user.
+func (t *Tracker) TrackAsAdmin(description string, remove func() error) {
+	t.add(resource{description: description, asAdmin: true, remove: remove})
+}
+
+func (t *Tracker) App(name string) {
+	t.Track("app "+name, t.cf("delete", name, "-f", "-r"))
+}
+
+// Route records host.domain, which deleting the apps mapped to it leaves
+// behind unless they are deleted with -r.
+func (t *Tracker) Route(domain, host string) {
+	t.Track("route "+host+"."+domain, t.cf("delete-route", domain, "-n", host, "-f"))
+}
+
+func (t *Tracker) ServiceInstance(name string) {
+	t.Track("service instance "+name, t.cf("delete-service", name, "-f"))
+}
+
+func (t *Tracker) Buildpack(name string) {
+	t.TrackAsAdmin("buildpack "+name, t.cf("delete-buildpack", name, "-f"))
+}
+
+func (t *Tracker) SecurityGroup(name string) {
+	t.TrackAsAdmin("security group "+name, t.cf("delete-security-group", name, "-f"))
+}
+
+// SecurityGroupBinding records that the security group was bound to a space.
+func (t *Tracker) SecurityGroupBinding(name, org, space string) {
+	t.TrackAsAdmin(securityGroupBinding(name, org, space), t.cf("unbind-security-group", name, org, space))
+}
+
+// SecurityGroupUnbound forgets a binding that the spec has unbound itself.
+func (t *Tracker) SecurityGroupUnbound(name, org, space string) {
+	t.forget(securityGroupBinding(name, org, space))
+}
+
+func securityGroupBinding(name, org, space string) string {
+	return fmt.Sprintf("security group %s binding to %s/%s", name, org, space)
+}
+
+// Space records a space created in org. The admin targets the org to delete
+// it.
+func (t *Tracker) Space(org, name string) {
+	target := t.cf("target", "-o", org)
+	deleteSpace := t.cf("delete-space", name, "-f")
+	t.TrackAsAdmin(orgSpace(org, name), func() error {
+		err := target()
+		if err != nil {
+			return err
+		}
+		return deleteSpace()
+	})
+}
+
+// SpaceDeleted forgets a space that the spec has deleted itself.
+func (t *Tracker) SpaceDeleted(org, name string) {
+	t.forget(orgSpace(org, name))
+}
+
+func orgSpace(org, name string) string {
+	return "space " + org + "/" + name
+}
+
+// SpaceRole records a space role granted to a user, which is unset again.
+func (t *Tracker) SpaceRole(username, org, space, role string) {
+	t.TrackAsAdmin(fmt.Sprintf("space role %s for %s in %s/%s", role, username, org, space),
+		t.cf("unset-space-role", username, org, space, role))
+}
+
+func (t *Tracker) Org(name string) {
+	t.TrackAsAdmin("org "+name, t.cf("delete-org", name, "-f"))
+}
+
+func (t *Tracker) Quota(name string) {
+	t.TrackAsAdmin("quota "+name, t.cf("delete-quota", name, "-f"))
+}
+
+func (t *Tracker) ServiceBroker(name string) {
+	t.TrackAsAdmin("service broker "+name, t.cf("delete-service-broker", name, "-f"))
+}
+
+// ServiceOffering records a broker's service so that it is purged, along with
+// any instances left behind, before the broker is deleted.
+func (t *Tracker) ServiceOffering(label string) {
+	t.TrackAsAdmin("service offering "+label, t.cf("purge-service-offering", label, "-f"))
+}
+
+// Cleanup removes every recorded resource, newest first, and forgets them.
+// It carries on past failures and returns them together as Leaks.
+func (t *Tracker) Cleanup() error {
+	t.lock.Lock()
+	resources := t.resources
+	t.resources = nil
+	t.lock.Unlock()
+
+	var leaks Leaks
+	for i := len(resources) - 1; i >= 0; i-- {
+		err := t.remove(resources[i])
+		if err != nil {
+			leaks = append(leaks, Leak{Description: resources[i].description, Err: err})
+		}
+	}
+
+	if len(leaks) > 0 {
+		return leaks
+	}
+	return nil
+}
+
+// Pending returns the descriptions of the recorded resources, oldest first.
+func (t *Tracker) Pending() []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	descriptions := []string{}
+	for _, resource := range t.resources {
+		descriptions = append(descriptions, resource.description)
+	}
+	return descriptions
+}
+
+func (t *Tracker) add(r resource) {
+	if !t.enabled {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.resources = append(t.resources, r)
+}
+
+// forget drops the newest resource with the description, which no longer
+// needs removing.
+func (t *Tracker) forget(description string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for i := len(t.resources) - 1; i >= 0; i-- {
+		if t.resources[i].description == description {
+			t.resources = append(t.resources[:i], t.resources[i+1:]...)
+			return
+		}
+	}
+}
+
+// remove runs a resource's remove function, turning failed assertions, e.g.
+// while logging in as the admin, and panics into errors so that one failure
+// doesn't stop the rest of the cleanup.
+func (t *Tracker) remove(r resource) (err error) {
+	failures := InterceptGomegaFailures(func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = fmt.Errorf("%v", recovered)
+			}
+		}()
+
+		if r.asAdmin {
+			cf.AsUser(t.adminContext, func() {
+				err = r.remove()
+			})
+		} else {
+			err = r.remove()
+		}
+	})
+
+	if err == nil && len(failures) > 0 {
+		err = fmt.Errorf("%s", strings.Join(failures, "\n"))
+	}
+	return err
+}
+
+func (t *Tracker) cf(args ...string) func() error {
+	return func() error {
+		session := cf.Cf(args...).Wait(t.Timeout)
+		if session.ExitCode() != 0 {
+			return fmt.Errorf("cf %s exited with %d:\n%s", strings.Join(args, " "), session.ExitCode(), output(session))
+		}
+		return nil
+	}
+}
+
+func output(session *gexec.Session) string {
+	return strings.TrimSpace(string(session.Out.Contents()) + string(session.Err.Contents()))
+}
@@ -0,0 +1,150 @@
+package cleanup_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tracker", func() {
+	var tracker *cleanup.Tracker
+
+	commands := func() []string {
+		lines := []string{}
+		for _, invocation := range fake.CF.Invocations() {
+			lines = append(lines, invocation.String())
+		}
+		return lines
+	}
+
+	BeforeEach(func() {
+		tracker = cleanup.NewTracker(cf.NewUserContext("api.example.com", "admin", "secret", "", "", false))
+	})
+
+	It("removes resources newest first", func() {
+		tracker.App("my-app")
+		tracker.ServiceInstance("my-db")
+
+		Expect(tracker.Cleanup()).To(Succeed())
+		Expect(commands()).To(Equal([]string{
+			"cf delete-service my-db -f",
+			"cf delete my-app -f -r",
+		}))
+	})
+
+	It("deletes routes after the apps they were mapped to", func() {
+		tracker.Route("example.com", "my-host")
+		tracker.App("my-app")
+
+		Expect(tracker.Cleanup()).To(Succeed())
+		Expect(commands()).To(Equal([]string{
+			"cf delete my-app -f -r",
+			"cf delete-route example.com -n my-host -f",
+		}))
+	})
+
+	It("removes admin resources as the admin user", func() {
+		tracker.SecurityGroup("my-sg")
+		tracker.SecurityGroupBinding("my-sg", "my-org", "my-space")
+
+		Expect(tracker.Cleanup()).To(Succeed())
+		Expect(commands()).To(Equal([]string{
+			"cf api api.example.com",
+			"cf auth admin secret",
+			"cf unbind-security-group my-sg my-org my-space",
+			"cf logout",
+			"cf api api.example.com",
+			"cf auth admin secret",
+			"cf delete-security-group my-sg -f",
+			"cf logout",
+		}))
+	})
+
+	It("does not unbind a security group the spec has unbound", func() {
+		tracker.SecurityGroup("my-sg")
+		tracker.SecurityGroupBinding("my-sg", "my-org", "my-space")
+		tracker.SecurityGroupUnbound("my-sg", "my-org", "my-space")
+
+		Expect(tracker.Pending()).To(Equal([]string{"security group my-sg"}))
+	})
+
+	It("targets a space's org to delete it", func() {
+		tracker.Space("my-org", "my-space")
+
+		Expect(tracker.Cleanup()).To(Succeed())
+		Expect(commands()[2:4]).To(Equal([]string{
+			"cf target -o my-org",
+			"cf delete-space my-space -f",
+		}))
+	})
+
+	It("does not delete a space the spec has deleted", func() {
+		tracker.Space("my-org", "my-space")
+		tracker.SpaceRole("my-user", "my-org", "other-space", "SpaceDeveloper")
+		tracker.SpaceDeleted("my-org", "my-space")
+
+		Expect(tracker.Pending()).To(Equal([]string{"space role SpaceDeveloper for my-user in my-org/other-space"}))
+	})
+
+	It("deletes an org before the quota it was created with", func() {
+		tracker.Quota("my-quota")
+		tracker.Org("my-org")
+
+		Expect(tracker.Cleanup()).To(Succeed())
+		Expect(fake.CF.InvocationsOf("cf delete-org")[0].String()).To(Equal("cf delete-org my-org -f"))
+		Expect(fake.CF.InvocationsOf("cf delete-quota")[0].String()).To(Equal("cf delete-quota my-quota -f"))
+		Expect(commands()).To(HaveLen(8))
+		Expect(commands()[2]).To(Equal("cf delete-org my-org -f"))
+	})
+
+	It("carries on past failures and reports what it could not remove", func() {
+		fake.CF.Handle("cf delete-buildpack", fakes.Failure(1, "FAILED\nBuildpack is locked"))
+		tracker.Buildpack("my-buildpack")
+		tracker.Track("custom resource", func() error { return errors.New("boom") })
+		tracker.App("my-app")
+
+		err := tracker.Cleanup()
+		Expect(err).To(HaveOccurred())
+
+		leaks, ok := err.(cleanup.Leaks)
+		Expect(ok).To(BeTrue())
+		Expect(leaks).To(HaveLen(2))
+		Expect(leaks[0].Description).To(Equal("custom resource"))
+		Expect(leaks[1].Description).To(Equal("buildpack my-buildpack"))
+		Expect(leaks[1].Err.Error()).To(ContainSubstring("Buildpack is locked"))
+
+		Expect(err.Error()).To(HavePrefix("failed to clean up 2 resource(s):\n  custom resource: boom\n"))
+		Expect(fake.CF.InvocationsOf("cf delete")).To(HaveLen(1))
+	})
+
+	It("turns failed assertions and panics into leaks", func() {
+		fake.CF.Handle("cf auth", fakes.Failure(1, "Authentication failed"))
+		tracker.ServiceBroker("my-broker")
+		tracker.Track("panicking resource", func() error { panic("oops") })
+
+		leaks := tracker.Cleanup().(cleanup.Leaks)
+		Expect(leaks).To(HaveLen(2))
+		Expect(leaks[0].Err.Error()).To(Equal("oops"))
+		Expect(leaks[1].Description).To(Equal("service broker my-broker"))
+	})
+
+	It("forgets resources once they have been cleaned up", func() {
+		tracker.App("my-app")
+		Expect(tracker.Pending()).To(Equal([]string{"app my-app"}))
+
+		Expect(tracker.Cleanup()).To(Succeed())
+		Expect(tracker.Pending()).To(BeEmpty())
+		Expect(tracker.Cleanup()).To(Succeed())
+		Expect(fake.CF.Invocations()).To(HaveLen(1))
+	})
+
+	It("ignores what is recorded before a tracker is installed", func() {
+		cleanup.Current().App("my-app")
+		Expect(cleanup.Current().Pending()).To(BeEmpty())
+	})
+})
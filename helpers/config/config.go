@@ -0,0 +1,136 @@
+// Package config loads and validates the configuration for a CATS suite.
+//
+// Values come from the $CONFIG JSON file, CATS_* environment variables and
+// -cats.* flags, in increasing order of precedence. When anything is
+// overridden, Load writes the effective config to a temporary file and points
+// $CONFIG at it, so that cf-test-helpers functions that call
+// helpers.LoadConfig themselves, such as helpers.CurlApp, see the same
+// values. For that to work nothing may call helpers.LoadConfig before the
+// suite's Test function runs Load. The file holds the admin password, so only
+// its owner can read it and suites defer RemoveEffectiveConfig to delete it.
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+)
+
+// Config is the configuration shared by all suites. It embeds the
+// cf-test-helpers config so it can be handed to helpers.NewContext and the
+// reporters as config.Config.
+type Config struct {
+	helpers.Config
+
+	IncludeServices          bool `json:"include_services"`
+	IncludeLogging           bool `json:"include_logging"`
+	IncludeV3                bool `json:"include_v3"`
+	IncludeOperator          bool `json:"include_operator"`
+	IncludeSecurityGroups    bool `json:"include_security_groups"`
+	IncludeInternetDependent bool `json:"include_internet_dependent"`
+	IncludeRoles             bool `json:"include_roles"`
+	IncludeQuotas            bool `json:"include_quotas"`
+	IncludeSSO               bool `json:"include_sso"`
+	IncludePersistentApp     bool `json:"include_persistent_app"`
+	IncludeAsyncPollExpiry   bool `json:"include_async_poll_expiry"`
+
+	AsyncServiceOperationTimeout time.Duration `json:"async_service_operation_timeout"`
+	BrokerMaxAsyncPollDuration   time.Duration `json:"broker_max_async_poll_duration"`
+
+	origins       map[string]string
+	effectivePath string
+}
+
+// Load resolves the config for the named suite from $CONFIG, the
+// environment and the command line, and panics with every problem found if
+// it is invalid.
+func Load(suite string) Config {
+	config, err := FromEnvironment(suite)
+	if err != nil {
+		panic(err)
+	}
+
+	if os.Getenv("CONFIG") == "" || config.Overridden() {
+		config.effectivePath, err = writeEffectiveConfig(config)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return config
+}
+
+// FromEnvironment resolves the config for the named suite from $CONFIG,
+// CATS_* environment variables and -cats.* flags without exporting it.
+func FromEnvironment(suite string) (Config, error) {
+	sources := Sources{
+		Environment: os.Environ(),
+		Flags:       SetFlags(),
+	}
+
+	path := os.Getenv("CONFIG")
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		sources.File = data
+	}
+
+	return Resolve(sources, suite)
+}
+
+// Parse validates a JSON config for the named suite and decodes it on top of
+// the schema defaults.
+func Parse(data []byte, suite string) (Config, error) {
+	return Resolve(Sources{File: data}, suite)
+}
+
+// Defaults returns a Config holding the schema default of every key.
+func Defaults() Config {
+	defaults := map[string]interface{}{}
+	for _, key := range Schema {
+		if key.Default != nil {
+			defaults[key.Name] = key.Default
+		}
+	}
+
+	var config Config
+	encoded, _ := json.Marshal(defaults)
+	json.Unmarshal(encoded, &config)
+	return config
+}
+
+// RemoveEffectiveConfig deletes the file Load wrote the effective config to,
+// if it wrote one.
+func (c Config) RemoveEffectiveConfig() error {
+	if c.effectivePath == "" {
+		return nil
+	}
+	return os.Remove(c.effectivePath)
+}
+
+func writeEffectiveConfig(config Config) (string, error) {
+	file, err := ioutil.TempFile("", "cats-config-")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	err = file.Chmod(0600)
+	if err == nil {
+		err = json.NewEncoder(file).Encode(config)
+	}
+	if err == nil {
+		err = os.Setenv("CONFIG", file.Name())
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
@@ -0,0 +1,64 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/config"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load", func() {
+	var configPath string
+
+	BeforeEach(func() {
+		file, err := ioutil.TempFile("", "cats-config-test-")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = file.WriteString(`{
+			"api": "api.example.com",
+			"apps_domain": "example.com",
+			"admin_user": "admin",
+			"admin_password": "hunter2"
+		}`)
+		Expect(err).NotTo(HaveOccurred())
+		file.Close()
+
+		configPath = file.Name()
+		os.Setenv("CONFIG", configPath)
+		os.Setenv("CATS_DEFAULT_TIMEOUT", "300")
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("CATS_DEFAULT_TIMEOUT")
+		os.Unsetenv("CONFIG")
+		os.Remove(configPath)
+	})
+
+	It("writes overrides to a file only its owner can read, until it is removed", func() {
+		loaded := config.Load("apps")
+
+		effectivePath := os.Getenv("CONFIG")
+		Expect(effectivePath).NotTo(Equal(configPath))
+
+		info, err := os.Stat(effectivePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		Expect(loaded.RemoveEffectiveConfig()).To(Succeed())
+		_, err = os.Stat(effectivePath)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("leaves the given file in place when nothing is overridden", func() {
+		os.Unsetenv("CATS_DEFAULT_TIMEOUT")
+
+		loaded := config.Load("apps")
+		Expect(os.Getenv("CONFIG")).To(Equal(configPath))
+
+		Expect(loaded.RemoveEffectiveConfig()).To(Succeed())
+		_, err := os.Stat(configPath)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
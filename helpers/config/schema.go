@@ -0,0 +1,235 @@
+package config
+
+import "path/filepath"
+
+type Type string
+
+const (
+	String  Type = "string"
+	Bool    Type = "bool"
+	Integer Type = "integer"
+	Seconds Type = "seconds"
+)
+
+// AllSuites marks a key that every suite needs.
+const AllSuites = "*"
+
+type Key struct {
+	Name        string
+	Type        Type
+	Default     interface{}
+	RequiredBy  []string
+	Secret      bool
+	Description string
+}
+
+// Required reports whether the suite cannot run without the key.
+func (k Key) Required(suite string) bool {
+	for _, requiredBy := range k.RequiredBy {
+		if requiredBy == AllSuites || requiredBy == suite {
+			return true
+		}
+	}
+	return false
+}
+
+// Schema documents every key accepted in the $CONFIG file.
+var Schema = []Key{
+	{
+		Name:        "api",
+		Type:        String,
+		RequiredBy:  []string{AllSuites},
+		Description: "Cloud Controller API endpoint, e.g. api.10.244.0.34.xip.io",
+	},
+	{
+		Name:        "apps_domain",
+		Type:        String,
+		RequiredBy:  []string{AllSuites},
+		Description: "Shared domain that pushed apps are routed on",
+	},
+	{
+		Name:        "admin_user",
+		Type:        String,
+		RequiredBy:  []string{AllSuites},
+		Description: "Admin user used to create orgs, spaces, users and quotas",
+	},
+	{
+		Name:        "admin_password",
+		Type:        String,
+		RequiredBy:  []string{AllSuites},
+		Secret:      true,
+		Description: "Password for admin_user",
+	},
+	{
+		Name:        "skip_ssl_validation",
+		Type:        Bool,
+		Default:     false,
+		Description: "Pass --skip-ssl-validation to cf and skip certificate checks in HTTP clients",
+	},
+	{
+		Name:        "persistent_app_host",
+		Type:        String,
+		Default:     "CATS-persistent-app",
+		Description: "Host name of the app kept between runs by the persistent app specs",
+	},
+	{
+		Name:        "persistent_app_space",
+		Type:        String,
+		Default:     "CATS-persistent-space",
+		Description: "Space holding the persistent app",
+	},
+	{
+		Name:        "persistent_app_org",
+		Type:        String,
+		Default:     "CATS-persistent-org",
+		Description: "Org holding the persistent app",
+	},
+	{
+		Name:        "persistent_app_quota_name",
+		Type:        String,
+		Default:     "CATS-persistent-quota",
+		Description: "Quota assigned to the persistent app's org",
+	},
+	{
+		Name:        "artifacts_directory",
+		Type:        String,
+		Default:     filepath.Join("..", "results"),
+		Description: "Directory for cf trace output and JUnit reports",
+	},
+	{
+		Name:        "default_timeout",
+		Type:        Seconds,
+		Default:     30,
+		Description: "Timeout for most cf commands",
+	},
+	{
+		Name:        "cf_push_timeout",
+		Type:        Seconds,
+		Default:     120,
+		Description: "Timeout for cf push and other staging commands",
+	},
+	{
+		Name:        "long_curl_timeout",
+		Type:        Seconds,
+		Default:     120,
+		Description: "Timeout for slow requests to apps",
+	},
+	{
+		Name:        "broker_start_timeout",
+		Type:        Seconds,
+		Default:     300,
+		Description: "Timeout for pushing and starting service brokers",
+	},
+	{
+		Name:        "async_service_operation_timeout",
+		Type:        Seconds,
+		Default:     300,
+		Description: "Timeout for async service broker operations to finish",
+	},
+	{
+		Name:        "broker_max_async_poll_duration",
+		Type:        Seconds,
+		Default:     600,
+		Description: "How long the Cloud Controller polls a broker for an async operation before failing it (its broker_client_max_async_poll_duration_minutes)",
+	},
+	{
+		Name:        "include_services",
+		Type:        Bool,
+		Default:     false,
+		Description: "Run the services suite",
+	},
+	{
+		Name:        "include_logging",
+		Type:        Bool,
+		Default:     false,
+		Description: "Run the logging suite, which needs a syslog drain reachable from the deployment",
+	},
+	{
+		Name:        "include_v3",
+		Type:        Bool,
+		Default:     false,
+		Description: "Run the v3 suite against the experimental v3 API",
+	},
+	{
+		Name:        "include_operator",
+		Type:        Bool,
+		Default:     false,
+		Description: "Run the operator suite, which needs deployment-specific setup",
+	},
+	{
+		Name:        "include_security_groups",
+		Type:        Bool,
+		Default:     true,
+		Description: "Run the security_groups suite",
+	},
+	{
+		Name:        "include_internet_dependent",
+		Type:        Bool,
+		Default:     true,
+		Description: "Run the internet_dependent suite, which needs outbound internet access from apps",
+	},
+	{
+		Name:        "include_roles",
+		Type:        Bool,
+		Default:     true,
+		Description: "Run the roles suite, which creates a user per org and space role",
+	},
+	{
+		Name:        "include_quotas",
+		Type:        Bool,
+		Default:     true,
+		Description: "Run the quotas suite, which creates org and space quotas with low limits",
+	},
+	{
+		Name:        "include_sso",
+		Type:        Bool,
+		Default:     true,
+		Description: "Run the SSO specs in the services suite",
+	},
+	{
+		Name:        "include_async_poll_expiry",
+		Type:        Bool,
+		Default:     false,
+		Description: "Run the services spec that waits broker_max_async_poll_duration for the Cloud Controller to stop polling a broker",
+	},
+	{
+		Name:        "include_persistent_app",
+		Type:        Bool,
+		Default:     true,
+		Description: "Run the specs that keep an app between runs in the persistent_app_* org and space",
+	},
+	{
+		Name:        "syslog_ip_address",
+		Type:        String,
+		RequiredBy:  []string{"logging"},
+		Description: "Address at which the deployment can reach the machine running the tests",
+	},
+	{
+		Name:        "syslog_drain_port",
+		Type:        Integer,
+		RequiredBy:  []string{"logging"},
+		Description: "Free port on the machine running the tests for the syslog drain listener",
+	},
+}
+
+// suiteToggles maps a suite to the key that switches it on. Keys that a suite
+// requires are only required while it is included.
+var suiteToggles = map[string]string{
+	"services":           "include_services",
+	"logging":            "include_logging",
+	"v3":                 "include_v3",
+	"operator":           "include_operator",
+	"security_groups":    "include_security_groups",
+	"internet_dependent": "include_internet_dependent",
+	"roles":              "include_roles",
+	"quotas":             "include_quotas",
+}
+
+func lookup(name string) (Key, bool) {
+	for _, key := range Schema {
+		if key.Name == name {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
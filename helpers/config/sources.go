@@ -0,0 +1,192 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// EnvironmentPrefix is prepended to the upper-cased key to form the name
+	// of its environment variable, e.g. CATS_DEFAULT_TIMEOUT.
+	EnvironmentPrefix = "CATS_"
+
+	// FlagPrefix is prepended to the key to form the name of its flag, e.g.
+	// -cats.default_timeout. Flags reach the suites through bin/test after --.
+	FlagPrefix = "cats."
+)
+
+// Sources are the layers a config is resolved from. Later layers win:
+// schema defaults, then File, then Environment, then Flags.
+type Sources struct {
+	// File is the JSON contents of $CONFIG, if any.
+	File []byte
+
+	// Environment is searched for CATS_* variables, as returned by os.Environ.
+	Environment []string
+
+	// Flags holds the values of the -cats.* flags that were set, by key name.
+	Flags map[string]string
+}
+
+var flagValues = map[string]*string{}
+
+func init() {
+	for _, key := range Schema {
+		flagValues[key.Name] = flag.String(FlagPrefix+key.Name, "", key.Description)
+	}
+}
+
+// SetFlags returns the -cats.* flags given on the command line.
+func SetFlags() map[string]string {
+	set := map[string]string{}
+	flag.Visit(func(f *flag.Flag) {
+		name := strings.TrimPrefix(f.Name, FlagPrefix)
+		if value, ok := flagValues[name]; ok && f.Name != name {
+			set[name] = *value
+		}
+	})
+	return set
+}
+
+// EnvironmentVariable returns the name of the variable that overrides key.
+func EnvironmentVariable(key Key) string {
+	return EnvironmentPrefix + strings.ToUpper(key.Name)
+}
+
+// Resolve layers the sources, validates the result for the suite and decodes
+// it. Errors from every layer are reported together.
+func Resolve(sources Sources, suite string) (Config, error) {
+	values := map[string]interface{}{}
+	origins := map[string]string{}
+	var errs Errors
+
+	if len(sources.File) > 0 {
+		fileValues, err := decodeObject(sources.File)
+		if err != nil {
+			return Config{}, Errors{err}
+		}
+		for name, value := range fileValues {
+			values[name] = value
+			origins[name] = "file"
+		}
+	}
+
+	environment := map[string]string{}
+	for _, variable := range sources.Environment {
+		parts := strings.SplitN(variable, "=", 2)
+		if len(parts) == 2 {
+			environment[parts[0]] = parts[1]
+		}
+	}
+
+	for _, key := range Schema {
+		variable := EnvironmentVariable(key)
+		text, ok := environment[variable]
+		if !ok {
+			continue
+		}
+
+		value, err := parseValue(key, text)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("environment variable %s: %s", variable, err))
+			continue
+		}
+		values[key.Name] = value
+		origins[key.Name] = "env " + variable
+	}
+
+	flagNames := []string{}
+	for name := range sources.Flags {
+		flagNames = append(flagNames, name)
+	}
+	sort.Strings(flagNames)
+
+	for _, name := range flagNames {
+		key, known := lookup(name)
+		if !known {
+			errs = append(errs, unknownKeyError(name))
+			continue
+		}
+
+		value, err := parseValue(key, sources.Flags[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("flag -%s%s: %s", FlagPrefix, name, err))
+			continue
+		}
+		values[name] = value
+		origins[name] = "flag -" + FlagPrefix + name
+	}
+
+	errs = append(errs, validateValues(values, suite)...)
+	if len(errs) > 0 {
+		return Config{}, errs
+	}
+
+	config := Defaults()
+	encoded, _ := json.Marshal(values)
+	err := json.Unmarshal(encoded, &config)
+	config.origins = origins
+	return config, err
+}
+
+func parseValue(key Key, text string) (interface{}, error) {
+	switch key.Type {
+	case Bool:
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not %s", text, typeDescription(key.Type))
+		}
+		return value, nil
+	case Integer, Seconds:
+		value, err := strconv.ParseUint(text, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not %s", text, typeDescription(key.Type))
+		}
+		return float64(value), nil
+	}
+	return text, nil
+}
+
+// Overridden reports whether any value came from the environment or a flag.
+func (c Config) Overridden() bool {
+	for _, origin := range c.origins {
+		if origin != "file" {
+			return true
+		}
+	}
+	return false
+}
+
+// Effective describes every key's value and where it came from, with secrets
+// redacted, for printing while debugging a run.
+func (c Config) Effective() string {
+	encoded, _ := json.Marshal(c)
+	values := map[string]interface{}{}
+	json.Unmarshal(encoded, &values)
+
+	var buffer bytes.Buffer
+	writer := tabwriter.NewWriter(&buffer, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "KEY\tVALUE\tSOURCE")
+	for _, key := range Schema {
+		value, _ := json.Marshal(values[key.Name])
+		if key.Secret && values[key.Name] != "" {
+			value = []byte("[REDACTED]")
+		}
+
+		origin, ok := c.origins[key.Name]
+		if !ok && key.Default != nil {
+			origin = "default"
+		} else if !ok {
+			origin = "unset"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", key.Name, value, origin)
+	}
+	writer.Flush()
+	return buffer.String()
+}
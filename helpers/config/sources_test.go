@@ -0,0 +1,103 @@
+package config_test
+
+import (
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/config"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Resolve", func() {
+	var sources config.Sources
+
+	BeforeEach(func() {
+		sources = config.Sources{
+			File: []byte(`{
+				"api": "api.example.com",
+				"apps_domain": "example.com",
+				"admin_user": "admin",
+				"admin_password": "hunter2",
+				"default_timeout": 45
+			}`),
+		}
+	})
+
+	It("uses the file when nothing is overridden", func() {
+		resolved, err := config.Resolve(sources, "apps")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(resolved.AppsDomain).To(Equal("example.com"))
+		Expect(int(resolved.DefaultTimeout)).To(Equal(45))
+		Expect(resolved.Overridden()).To(BeFalse())
+	})
+
+	It("lets CATS_* environment variables override the file", func() {
+		sources.Environment = []string{
+			"PATH=/bin",
+			"CATS_APPS_DOMAIN=other.example.com",
+			"CATS_DEFAULT_TIMEOUT=60",
+			"CATS_SKIP_SSL_VALIDATION=true",
+		}
+
+		resolved, err := config.Resolve(sources, "apps")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(resolved.AppsDomain).To(Equal("other.example.com"))
+		Expect(int(resolved.DefaultTimeout)).To(Equal(60))
+		Expect(resolved.SkipSSLValidation).To(BeTrue())
+		Expect(resolved.AdminUser).To(Equal("admin"))
+		Expect(resolved.Overridden()).To(BeTrue())
+	})
+
+	It("lets flags override the environment", func() {
+		sources.Environment = []string{"CATS_DEFAULT_TIMEOUT=60"}
+		sources.Flags = map[string]string{"default_timeout": "90"}
+
+		resolved, err := config.Resolve(sources, "apps")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(int(resolved.DefaultTimeout)).To(Equal(90))
+	})
+
+	It("can supply required keys without a file", func() {
+		resolved, err := config.Resolve(config.Sources{
+			Environment: []string{"CATS_API=api.example.com", "CATS_APPS_DOMAIN=example.com", "CATS_ADMIN_USER=admin"},
+			Flags:       map[string]string{"admin_password": "admin"},
+		}, "apps")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(resolved.ApiEndpoint).To(Equal("api.example.com"))
+		Expect(resolved.PersistentAppHost).To(Equal("CATS-persistent-app"))
+	})
+
+	It("reports bad values from every layer together", func() {
+		sources.Environment = []string{"CATS_DEFAULT_TIMEOUT=soon", "CATS_INCLUDE_LOGGING=true"}
+		sources.Flags = map[string]string{"skip_ssl_validation": "maybe", "apps_domian": "x"}
+
+		_, err := config.Resolve(sources, "logging")
+		Expect(err).To(HaveOccurred())
+		Expect(err.(config.Errors)).To(HaveLen(5))
+		Expect(err.Error()).To(ContainSubstring("environment variable CATS_DEFAULT_TIMEOUT: 'soon' is not a non-negative integer number of seconds"))
+		Expect(err.Error()).To(ContainSubstring("flag -cats.skip_ssl_validation: 'maybe' is not true or false"))
+		Expect(err.Error()).To(ContainSubstring("unknown key 'apps_domian' (did you mean 'apps_domain'?)"))
+		Expect(err.Error()).To(ContainSubstring("missing key 'syslog_ip_address'"))
+	})
+
+	Describe("Effective", func() {
+		It("shows each value with its source and redacts secrets", func() {
+			sources.Environment = []string{"CATS_CF_PUSH_TIMEOUT=300"}
+			sources.Flags = map[string]string{"syslog_drain_port": "8514"}
+
+			resolved, err := config.Resolve(sources, "apps")
+			Expect(err).NotTo(HaveOccurred())
+
+			effective := resolved.Effective()
+			Expect(effective).NotTo(ContainSubstring("hunter2"))
+			Expect(effective).To(MatchRegexp(`admin_password +\[REDACTED\] +file`))
+			Expect(effective).To(MatchRegexp(`default_timeout +45 +file`))
+			Expect(effective).To(MatchRegexp(`cf_push_timeout +300 +env CATS_CF_PUSH_TIMEOUT`))
+			Expect(effective).To(MatchRegexp(`syslog_drain_port +8514 +flag -cats.syslog_drain_port`))
+			Expect(effective).To(MatchRegexp(`persistent_app_org +"CATS-persistent-org" +default`))
+			Expect(effective).To(MatchRegexp(`syslog_ip_address +"" +unset`))
+		})
+	})
+})
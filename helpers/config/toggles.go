@@ -0,0 +1,85 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+)
+
+var usedTags = map[string]bool{}
+
+// Tag returns the marker for specs that only run while the include_* key is
+// true. Add it to the text of the container that holds them:
+//
+//	var _ = Describe("SSO Lifecycle "+catsconfig.Tag("include_sso"), func() {
+//
+// and call SkipExcludedSpecs before running the suite.
+func Tag(toggle string) string {
+	key, known := lookup(toggle)
+	if !known || key.Type != Bool || !strings.HasPrefix(toggle, "include_") {
+		panic(fmt.Sprintf("'%s' is not an include_* config key", toggle))
+	}
+
+	usedTags[toggle] = true
+	return "[" + toggle + "]"
+}
+
+// Included returns the value of an include_* key.
+func (c Config) Included(toggle string) bool {
+	encoded, _ := json.Marshal(c)
+	values := map[string]interface{}{}
+	json.Unmarshal(encoded, &values)
+
+	included, ok := values[toggle].(bool)
+	if !ok {
+		panic(fmt.Sprintf("'%s' is not an include_* config key", toggle))
+	}
+	return included
+}
+
+// SkipSuiteUnless skips the suite, saying why, when the toggle is false.
+func (c Config) SkipSuiteUnless(t *testing.T, toggle string) {
+	if c.Included(toggle) {
+		return
+	}
+
+	reason := fmt.Sprintf("Skipping suite: %s is false. %s", toggle, howToInclude(toggle))
+	fmt.Println(reason)
+	t.Skip(reason)
+}
+
+// SkipExcludedSpecs adds the tags of toggles that are false to ginkgo's skip
+// pattern and prints why those specs will be reported as skipped.
+func (c Config) SkipExcludedSpecs() {
+	toggles := []string{}
+	for toggle := range usedTags {
+		toggles = append(toggles, toggle)
+	}
+	sort.Strings(toggles)
+
+	patterns := []string{}
+	if ginkgoconfig.GinkgoConfig.SkipString != "" {
+		patterns = append(patterns, "(?:"+ginkgoconfig.GinkgoConfig.SkipString+")")
+	}
+
+	for _, toggle := range toggles {
+		if c.Included(toggle) {
+			continue
+		}
+
+		fmt.Printf("Skipping specs tagged %s: %s is false. %s\n", Tag(toggle), toggle, howToInclude(toggle))
+		patterns = append(patterns, regexp.QuoteMeta(Tag(toggle)))
+	}
+
+	ginkgoconfig.GinkgoConfig.SkipString = strings.Join(patterns, "|")
+}
+
+func howToInclude(toggle string) string {
+	key, _ := lookup(toggle)
+	return fmt.Sprintf("Set it to true in $CONFIG, with %s=true or with -%s%s=true.", EnvironmentVariable(key), FlagPrefix, toggle)
+}
@@ -0,0 +1,77 @@
+package config_test
+
+import (
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/config"
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Toggles", func() {
+	var resolved config.Config
+
+	BeforeEach(func() {
+		var err error
+		resolved, err = config.Resolve(config.Sources{
+			File:  []byte(minimalConfig),
+			Flags: map[string]string{"include_services": "true", "include_sso": "false"},
+		}, "services")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("defaults the optional suites off and the finer toggles on", func() {
+		defaults, err := config.Parse([]byte(minimalConfig), "apps")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(defaults.Included("include_services")).To(BeFalse())
+		Expect(defaults.Included("include_logging")).To(BeFalse())
+		Expect(defaults.Included("include_v3")).To(BeFalse())
+		Expect(defaults.Included("include_operator")).To(BeFalse())
+		Expect(defaults.Included("include_security_groups")).To(BeTrue())
+		Expect(defaults.Included("include_persistent_app")).To(BeTrue())
+	})
+
+	It("reads include_* keys by name", func() {
+		Expect(resolved.Included("include_services")).To(BeTrue())
+		Expect(resolved.IncludeServices).To(BeTrue())
+		Expect(resolved.Included("include_sso")).To(BeFalse())
+		Expect(func() { resolved.Included("api") }).To(Panic())
+	})
+
+	It("only accepts include_* keys as tags", func() {
+		Expect(config.Tag("include_sso")).To(Equal("[include_sso]"))
+		Expect(func() { config.Tag("api") }).To(Panic())
+		Expect(func() { config.Tag("include_everything") }).To(Panic())
+	})
+
+	Describe("SkipExcludedSpecs", func() {
+		var originalSkip string
+
+		BeforeEach(func() {
+			originalSkip = ginkgoconfig.GinkgoConfig.SkipString
+			config.Tag("include_sso")
+			config.Tag("include_persistent_app")
+		})
+
+		AfterEach(func() {
+			ginkgoconfig.GinkgoConfig.SkipString = originalSkip
+		})
+
+		It("skips the tags of toggles that are off, keeping any existing skip pattern", func() {
+			ginkgoconfig.GinkgoConfig.SkipString = "slow"
+			resolved.SkipExcludedSpecs()
+
+			Expect(ginkgoconfig.GinkgoConfig.SkipString).To(Equal(`(?:slow)|\[include_sso\]`))
+		})
+
+		It("leaves the skip pattern empty when everything is included", func() {
+			ginkgoconfig.GinkgoConfig.SkipString = ""
+			included, err := config.Parse([]byte(minimalConfig), "apps")
+			Expect(err).NotTo(HaveOccurred())
+
+			included.SkipExcludedSpecs()
+			Expect(ginkgoconfig.GinkgoConfig.SkipString).To(BeEmpty())
+		})
+	})
+})
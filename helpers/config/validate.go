@@ -0,0 +1,188 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Errors collects every problem found in a config file.
+type Errors []error
+
+func (errs Errors) Error() string {
+	lines := []string{fmt.Sprintf("invalid configuration (%d problems):", len(errs))}
+	for _, err := range errs {
+		lines = append(lines, "  - "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate checks a JSON config against the Schema for the given suite. It
+// reports unknown keys, values of the wrong type and missing required keys
+// together rather than stopping at the first one.
+func Validate(data []byte, suite string) error {
+	values, err := decodeObject(data)
+	if err != nil {
+		return Errors{err}
+	}
+
+	errs := validateValues(values, suite)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func decodeObject(data []byte) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	err := json.Unmarshal(data, &values)
+	if err != nil {
+		return nil, fmt.Errorf("not a JSON object: %s", err)
+	}
+	return values, nil
+}
+
+func validateValues(values map[string]interface{}, suite string) Errors {
+	var errs Errors
+
+	names := []string{}
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key, known := lookup(name)
+		if !known {
+			errs = append(errs, unknownKeyError(name))
+			continue
+		}
+
+		err := checkType(key, values[name])
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, key := range Schema {
+		if !key.Required(suite) || (!key.Required("") && !suiteIncluded(values, suite)) {
+			continue
+		}
+
+		value, present := values[key.Name]
+		if !present || value == nil || value == "" {
+			errs = append(errs, fmt.Errorf("missing key '%s' (required by %s)", key.Name, requiredByDescription(key, suite)))
+		}
+	}
+
+	return errs
+}
+
+func suiteIncluded(values map[string]interface{}, suite string) bool {
+	toggle, ok := suiteToggles[suite]
+	if !ok {
+		return true
+	}
+
+	key, _ := lookup(toggle)
+	included, ok := values[toggle].(bool)
+	if !ok {
+		included, _ = key.Default.(bool)
+	}
+	return included
+}
+
+func checkType(key Key, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var ok bool
+	switch key.Type {
+	case String:
+		_, ok = value.(string)
+	case Bool:
+		_, ok = value.(bool)
+	case Integer, Seconds:
+		var number float64
+		number, ok = value.(float64)
+		ok = ok && number == math.Trunc(number) && number >= 0
+	}
+
+	if !ok {
+		return fmt.Errorf("key '%s' must be %s, got %s", key.Name, typeDescription(key.Type), jsonDescription(value))
+	}
+	return nil
+}
+
+func typeDescription(t Type) string {
+	switch t {
+	case Bool:
+		return "true or false"
+	case Integer:
+		return "a non-negative integer"
+	case Seconds:
+		return "a non-negative integer number of seconds"
+	}
+	return "a string"
+}
+
+func jsonDescription(value interface{}) string {
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+func requiredByDescription(key Key, suite string) string {
+	if key.Required("") {
+		return "all suites"
+	}
+	return "the " + suite + " suite"
+}
+
+func unknownKeyError(name string) error {
+	closest, closestDistance := "", 3
+	for _, key := range Schema {
+		distance := editDistance(name, key.Name)
+		if distance < closestDistance {
+			closest, closestDistance = key.Name, distance
+		}
+	}
+
+	if closest != "" {
+		return fmt.Errorf("unknown key '%s' (did you mean '%s'?)", name, closest)
+	}
+	return fmt.Errorf("unknown key '%s'", name)
+}
+
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minimum(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func minimum(values ...int) int {
+	smallest := values[0]
+	for _, value := range values[1:] {
+		if value < smallest {
+			smallest = value
+		}
+	}
+	return smallest
+}
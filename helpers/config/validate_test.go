@@ -0,0 +1,118 @@
+package config_test
+
+import (
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/config"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const minimalConfig = `{
+	"api": "api.example.com",
+	"apps_domain": "example.com",
+	"admin_user": "admin",
+	"admin_password": "admin"
+}`
+
+var _ = Describe("Validate", func() {
+	It("accepts a config with the required keys", func() {
+		Expect(config.Validate([]byte(minimalConfig), "apps")).To(Succeed())
+	})
+
+	It("reports every problem at once", func() {
+		err := config.Validate([]byte(`{
+			"api": "api.example.com",
+			"admin_user": "admin",
+			"defualt_timeout": 30,
+			"cf_push_timeout": "2m",
+			"skip_ssl_validation": "yes",
+			"syslog_drain_port": 514.5,
+			"include_logging": true
+		}`), "logging")
+
+		Expect(err).To(BeAssignableToTypeOf(config.Errors{}))
+		Expect(err.(config.Errors)).To(HaveLen(7))
+		Expect(err.Error()).To(ContainSubstring("invalid configuration (7 problems):"))
+		Expect(err.Error()).To(ContainSubstring("unknown key 'defualt_timeout' (did you mean 'default_timeout'?)"))
+		Expect(err.Error()).To(ContainSubstring(`key 'cf_push_timeout' must be a non-negative integer number of seconds, got "2m"`))
+		Expect(err.Error()).To(ContainSubstring(`key 'skip_ssl_validation' must be true or false, got "yes"`))
+		Expect(err.Error()).To(ContainSubstring("key 'syslog_drain_port' must be a non-negative integer, got 514.5"))
+		Expect(err.Error()).To(ContainSubstring("missing key 'apps_domain' (required by all suites)"))
+		Expect(err.Error()).To(ContainSubstring("missing key 'admin_password' (required by all suites)"))
+		Expect(err.Error()).To(ContainSubstring("missing key 'syslog_ip_address' (required by the logging suite)"))
+	})
+
+	It("does not suggest a key for names that are nothing alike", func() {
+		err := config.Validate([]byte(`{"api": "a", "apps_domain": "b", "admin_user": "c", "admin_password": "d", "flavour": "mint"}`), "apps")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HaveSuffix("unknown key 'flavour'"))
+	})
+
+	It("only requires suite keys for that suite, while it is included", func() {
+		withLogging := []byte(`{
+			"api": "api.example.com",
+			"apps_domain": "example.com",
+			"admin_user": "admin",
+			"admin_password": "admin",
+			"include_logging": true
+		}`)
+
+		Expect(config.Validate(withLogging, "apps")).To(Succeed())
+		Expect(config.Validate(withLogging, "logging")).To(MatchError(ContainSubstring("missing key 'syslog_ip_address'")))
+		Expect(config.Validate([]byte(minimalConfig), "logging")).To(Succeed())
+	})
+
+	It("treats empty strings as missing", func() {
+		err := config.Validate([]byte(`{"api": "", "apps_domain": "b", "admin_user": "c", "admin_password": "d"}`), "apps")
+		Expect(err).To(MatchError(ContainSubstring("missing key 'api'")))
+	})
+
+	It("rejects input that is not a JSON object", func() {
+		Expect(config.Validate([]byte(`["api"]`), "apps")).To(MatchError(ContainSubstring("not a JSON object")))
+	})
+})
+
+var _ = Describe("Parse", func() {
+	It("fills in schema defaults", func() {
+		parsed, err := config.Parse([]byte(minimalConfig), "apps")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(parsed.ApiEndpoint).To(Equal("api.example.com"))
+		Expect(parsed.AdminPassword).To(Equal("admin"))
+		Expect(parsed.PersistentAppOrg).To(Equal("CATS-persistent-org"))
+		Expect(parsed.ArtifactsDirectory).To(Equal("../results"))
+		Expect(int(parsed.DefaultTimeout)).To(Equal(30))
+		Expect(parsed.SkipSSLValidation).To(BeFalse())
+	})
+
+	It("lets the file override defaults", func() {
+		parsed, err := config.Parse([]byte(`{
+			"api": "api.example.com",
+			"apps_domain": "example.com",
+			"admin_user": "admin",
+			"admin_password": "admin",
+			"persistent_app_org": "my-org",
+			"default_timeout": 45,
+			"syslog_drain_port": 8514
+		}`), "apps")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(parsed.PersistentAppOrg).To(Equal("my-org"))
+		Expect(int(parsed.DefaultTimeout)).To(Equal(45))
+		Expect(parsed.SyslogDrainPort).To(Equal(8514))
+	})
+
+	It("returns validation errors", func() {
+		_, err := config.Parse([]byte(`{}`), "apps")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Schema", func() {
+	It("documents every key", func() {
+		for _, key := range config.Schema {
+			Expect(key.Description).NotTo(BeEmpty(), key.Name)
+			Expect(key.Type).NotTo(BeEmpty(), key.Name)
+		}
+	})
+})
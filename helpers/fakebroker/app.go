@@ -0,0 +1,35 @@
+package fakebroker
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/gomega"
+)
+
+const appPackage = "github.com/cloudfoundry/cf-acceptance-tests/helpers/fakebroker/fake_service_broker"
+
+// BuildApp compiles fake_service_broker for Linux into a new directory, ready
+// to be pushed with the binary buildpack:
+//
+//	cf push my-broker -p dir -b binary_buildpack
+//
+// The app serves a Broker with the DefaultCatalog, or the catalog in $CATALOG,
+// and requires $BROKER_USERNAME and $BROKER_PASSWORD when they are set.
+// Remove the directory once done, e.g. in an AfterSuite.
+func BuildApp() string {
+	dir, err := ioutil.TempDir("", "fake_service_broker")
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	build := exec.Command("go", "build", "-o", filepath.Join(dir, "fake_service_broker"), appPackage)
+	build.Env = append(os.Environ(), "GOOS=linux", "GOARCH=amd64", "CGO_ENABLED=0")
+	output, err := build.CombinedOutput()
+	ExpectWithOffset(1, err).NotTo(HaveOccurred(), string(output))
+
+	err = ioutil.WriteFile(filepath.Join(dir, "Procfile"), []byte("web: ./fake_service_broker\n"), 0644)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	return dir
+}
@@ -0,0 +1,191 @@
+package fakebroker_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakebroker"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Broker", func() {
+	var broker *fakebroker.Broker
+	var server *httptest.Server
+
+	send := func(method, path, body string) (int, map[string]interface{}) {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		req.SetBasicAuth("username", "password")
+		req.Header.Set("X-Broker-Api-Version", "2.5")
+
+		response, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+
+		raw, err := ioutil.ReadAll(response.Body)
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded := map[string]interface{}{}
+		Expect(json.Unmarshal(raw, &decoded)).To(Succeed(), string(raw))
+		return response.StatusCode, decoded
+	}
+
+	BeforeEach(func() {
+		broker = fakebroker.New(fakebroker.DefaultCatalog())
+		server = httptest.NewServer(broker)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("serves the catalog", func() {
+		status, body := send("GET", "/v2/catalog", "")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body["services"]).To(HaveLen(1))
+
+		catalog := fakebroker.DefaultCatalog()
+		catalog.Services[0].Name = "other-service"
+		broker.SetCatalog(catalog)
+
+		_, body = send("GET", "/v2/catalog", "")
+		service := body["services"].([]interface{})[0].(map[string]interface{})
+		Expect(service["name"]).To(Equal("other-service"))
+	})
+
+	It("answers every operation like the service_broker asset by default", func() {
+		status, _ := send("PUT", "/v2/service_instances/instance-1", `{"plan_id":"plan"}`)
+		Expect(status).To(Equal(http.StatusCreated))
+
+		status, _ = send("PATCH", "/v2/service_instances/instance-1", `{}`)
+		Expect(status).To(Equal(http.StatusOK))
+
+		status, body := send("PUT", "/v2/service_instances/instance-1/service_bindings/binding-1", `{}`)
+		Expect(status).To(Equal(http.StatusCreated))
+		Expect(body["credentials"]).To(HaveKeyWithValue("username", "fake-user"))
+
+		status, _ = send("DELETE", "/v2/service_instances/instance-1/service_bindings/binding-1", "")
+		Expect(status).To(Equal(http.StatusOK))
+
+		status, body = send("GET", "/v2/service_instances/instance-1/last_operation", "")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(HaveKeyWithValue("state", "succeeded"))
+
+		status, _ = send("DELETE", "/v2/service_instances/instance-1", "")
+		Expect(status).To(Equal(http.StatusOK))
+	})
+
+	It("echoes the parameters of a binding in its credentials", func() {
+		_, body := send("PUT", "/v2/service_instances/instance-1/service_bindings/binding-1", `{"parameters":{"nested":{"unicode":"\u2603"}}}`)
+		Expect(body["credentials"]).To(HaveKeyWithValue("parameters", map[string]interface{}{
+			"nested": map[string]interface{}{"unicode": "☃"},
+		}))
+	})
+
+	It("journals every request to the v2 API", func() {
+		send("PUT", "/v2/service_instances/instance-1", `{"plan_id":"plan"}`)
+		send("PUT", "/v2/service_instances/instance-1/service_bindings/binding-1", `{"app_guid":"app"}`)
+
+		journal := broker.Journal()
+		Expect(journal).To(HaveLen(2))
+
+		Expect(journal[0].Operation).To(Equal(fakebroker.Provision))
+		Expect(journal[0].Method).To(Equal("PUT"))
+		Expect(journal[0].InstanceID).To(Equal("instance-1"))
+		Expect(journal[0].Body).To(Equal(`{"plan_id":"plan"}`))
+		Expect(journal[0].Header.Get("X-Broker-Api-Version")).To(Equal("2.5"))
+
+		Expect(journal[1].Operation).To(Equal(fakebroker.Bind))
+		Expect(journal[1].InstanceID).To(Equal("instance-1"))
+		Expect(journal[1].BindingID).To(Equal("binding-1"))
+	})
+
+	It("doesn't answer requests outside the v2 API", func() {
+		response, err := http.Get(server.URL + "/v2/unknown")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(broker.Journal()).To(BeEmpty())
+	})
+
+	Describe("scripted responses", func() {
+		It("answers with them in turn and keeps the last one", func() {
+			broker.Script(fakebroker.LastOperation,
+				fakebroker.State("in progress", "10% done"),
+				fakebroker.State("failed", "out of disks"),
+			)
+
+			_, body := send("GET", "/v2/service_instances/instance-1/last_operation", "")
+			Expect(body).To(Equal(map[string]interface{}{"state": "in progress", "description": "10% done"}))
+
+			for i := 0; i < 2; i++ {
+				_, body = send("GET", "/v2/service_instances/instance-1/last_operation", "")
+				Expect(body).To(Equal(map[string]interface{}{"state": "failed", "description": "out of disks"}))
+			}
+		})
+
+		It("only applies to their operation", func() {
+			broker.Script(fakebroker.Provision, fakebroker.Async())
+
+			status, _ := send("PUT", "/v2/service_instances/instance-1", "{}")
+			Expect(status).To(Equal(http.StatusAccepted))
+
+			status, _ = send("DELETE", "/v2/service_instances/instance-1", "")
+			Expect(status).To(Equal(http.StatusOK))
+		})
+
+		It("answers with errors", func() {
+			broker.Script(fakebroker.Deprovision, fakebroker.Gone())
+			broker.Script(fakebroker.Bind, fakebroker.Error(http.StatusConflict, "already bound"))
+
+			status, _ := send("DELETE", "/v2/service_instances/instance-1", "")
+			Expect(status).To(Equal(http.StatusGone))
+
+			status, body := send("PUT", "/v2/service_instances/instance-1/service_bindings/binding-1", "{}")
+			Expect(status).To(Equal(http.StatusConflict))
+			Expect(body).To(HaveKeyWithValue("description", "already bound"))
+		})
+
+		It("delays the answer", func() {
+			broker.Script(fakebroker.Provision, fakebroker.Response{Status: http.StatusCreated, Delay: 50 * time.Millisecond})
+
+			start := time.Now()
+			send("PUT", "/v2/service_instances/instance-1", "{}")
+			Expect(time.Since(start)).To(BeNumerically(">=", 50*time.Millisecond))
+		})
+
+		It("goes back to the default once reset", func() {
+			broker.Script(fakebroker.Provision, fakebroker.Async())
+			send("PUT", "/v2/service_instances/instance-1", "{}")
+
+			broker.Reset()
+			Expect(broker.Journal()).To(BeEmpty())
+
+			status, _ := send("PUT", "/v2/service_instances/instance-1", "{}")
+			Expect(status).To(Equal(http.StatusCreated))
+		})
+	})
+
+	Context("with credentials", func() {
+		BeforeEach(func() {
+			broker.Username = "username"
+			broker.Password = "password"
+		})
+
+		It("accepts requests that send them", func() {
+			status, _ := send("GET", "/v2/catalog", "")
+			Expect(status).To(Equal(http.StatusOK))
+		})
+
+		It("rejects requests that don't", func() {
+			response, err := http.Get(server.URL + "/v2/catalog")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+	})
+})
@@ -0,0 +1,50 @@
+package fakebroker
+
+// Catalog is what the broker answers GET /v2/catalog with.
+type Catalog struct {
+	Services []Service `json:"services"`
+}
+
+type Service struct {
+	ID              string           `json:"id"`
+	Name            string           `json:"name"`
+	Description     string           `json:"description"`
+	Bindable        bool             `json:"bindable"`
+	PlanUpdateable  bool             `json:"plan_updateable"`
+	Tags            []string         `json:"tags,omitempty"`
+	DashboardClient *DashboardClient `json:"dashboard_client,omitempty"`
+	Plans           []Plan           `json:"plans"`
+}
+
+type Plan struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// Free is left to the Cloud Controller's default, free, when nil.
+	Free *bool `json:"free,omitempty"`
+}
+
+type DashboardClient struct {
+	ID          string `json:"id"`
+	Secret      string `json:"secret"`
+	RedirectURI string `json:"redirect_uri"`
+}
+
+// DefaultCatalog offers the same bindable service and plan as the
+// service_broker asset does without configuration.
+func DefaultCatalog() Catalog {
+	return Catalog{Services: []Service{{
+		ID:             "f479b64b-7c25-42e6-8d8f-e6d22c456c9b",
+		Name:           "fake-service",
+		Description:    "fake service",
+		Bindable:       true,
+		PlanUpdateable: true,
+		Tags:           []string{"no-sql", "relational"},
+		Plans: []Plan{{
+			ID:          "f52eabf8-e38d-422f-8ef9-9dc83b75cc05",
+			Name:        "fake-plan",
+			Description: "Shared fake Server, 5tb persistent disk, 40 max concurrent connections",
+		}},
+	}}}
+}
@@ -0,0 +1,32 @@
+// fake_service_broker serves a fakebroker.Broker on $PORT, so that it can be
+// pushed as an app and registered with the Cloud Controller.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakebroker"
+)
+
+func main() {
+	catalog := fakebroker.DefaultCatalog()
+	if config := os.Getenv("CATALOG"); config != "" {
+		if err := json.Unmarshal([]byte(config), &catalog); err != nil {
+			fmt.Fprintln(os.Stderr, "fake_service_broker: invalid $CATALOG:", err)
+			os.Exit(1)
+		}
+	}
+
+	broker := fakebroker.New(catalog)
+	broker.Username = os.Getenv("BROKER_USERNAME")
+	broker.Password = os.Getenv("BROKER_PASSWORD")
+
+	fmt.Println("listening...")
+	if err := http.ListenAndServe(":"+os.Getenv("PORT"), broker); err != nil {
+		fmt.Fprintln(os.Stderr, "fake_service_broker:", err)
+		os.Exit(1)
+	}
+}
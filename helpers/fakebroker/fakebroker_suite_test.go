@@ -0,0 +1,13 @@
+package fakebroker_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestFakebroker(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fakebroker Suite")
+}
@@ -0,0 +1,55 @@
+package fakebroker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// journalPath is where brokers serve their journal: the fake broker as well
+// as the service_broker and async_service_broker assets, which record
+// requests in the same format but leave it to Remote to classify them.
+const journalPath = "/journal"
+
+// ProvisionDetails is the body of provision and update requests.
+type ProvisionDetails struct {
+	ServiceID        string `json:"service_id"`
+	PlanID           string `json:"plan_id"`
+	OrganizationGUID string `json:"organization_guid"`
+	SpaceGUID        string `json:"space_guid"`
+
+	// Parameters is the JSON given to create-service or update-service with -c.
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// BindDetails is the body of bind requests.
+type BindDetails struct {
+	ServiceID string `json:"service_id"`
+	PlanID    string `json:"plan_id"`
+	AppGUID   string `json:"app_guid"`
+
+	// Parameters is the JSON given to bind-service with -c.
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// Credentials returns the basic auth credentials the request was sent with.
+func (r Request) Credentials() (username, password string, ok bool) {
+	return (&http.Request{Header: r.Header}).BasicAuth()
+}
+
+// QueryParam returns the first value of the query parameter, e.g. the plan_id
+// of an unbind or deprovision request.
+func (r Request) QueryParam(name string) string {
+	values, _ := url.ParseQuery(r.Query)
+	return values.Get(name)
+}
+
+// DecodeBody decodes the JSON body into value, e.g. a ProvisionDetails.
+func (r Request) DecodeBody(value interface{}) error {
+	return json.Unmarshal([]byte(r.Body), value)
+}
+
+func (b *Broker) serveJournal(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(b.Journal())
+}
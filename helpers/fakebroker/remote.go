@@ -0,0 +1,144 @@
+package fakebroker
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// configPrefix is where a Broker takes the configuration Remote sends it.
+// These requests are not part of the v2 API and are not journaled.
+const configPrefix = "/config"
+
+func (b *Broker) serveConfig(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, configPrefix)
+
+	switch {
+	case path == "" && req.Method == "DELETE":
+		b.Reset()
+	case path == "/catalog" && req.Method == "PUT":
+		var catalog Catalog
+		if !decode(w, req, &catalog) {
+			return
+		}
+		b.SetCatalog(catalog)
+	case strings.HasPrefix(path, "/responses/") && req.Method == "PUT":
+		var responses []Response
+		if !decode(w, req, &responses) {
+			return
+		}
+		b.Script(Operation(strings.TrimPrefix(path, "/responses/")), responses...)
+	default:
+		http.NotFound(w, req)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decode(w http.ResponseWriter, req *http.Request, value interface{}) bool {
+	if err := json.NewDecoder(req.Body).Decode(value); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// Remote configures a Broker served elsewhere, typically one pushed as an
+// app, as the Broker's own methods do in-process.
+type Remote struct {
+	URL string
+
+	client *http.Client
+}
+
+func NewRemote(url string, skipSSLValidation bool) *Remote {
+	return &Remote{
+		URL: strings.TrimSuffix(url, "/"),
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: skipSSLValidation},
+			},
+		},
+	}
+}
+
+// Journal returns the v2 API requests the broker received so far, oldest
+// first.
+func (r *Remote) Journal() []Request {
+	journal, err := r.journal()
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	return journal
+}
+
+func (r *Remote) journal() ([]Request, error) {
+	response, err := r.client.Get(r.URL + journalPath)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		message, _ := ioutil.ReadAll(response.Body)
+		return nil, fmt.Errorf("GET %s: %d %s", r.URL+journalPath, response.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	journal := []Request{}
+	if err := json.NewDecoder(response.Body).Decode(&journal); err != nil {
+		return nil, err
+	}
+	for i := range journal {
+		if journal[i].Operation == "" {
+			classify(&journal[i])
+		}
+	}
+	return journal, nil
+}
+
+func (r *Remote) SetCatalog(catalog Catalog) {
+	ExpectWithOffset(1, r.send("PUT", "/catalog", catalog)).To(Succeed())
+}
+
+func (r *Remote) Script(operation Operation, responses ...Response) {
+	if responses == nil {
+		responses = []Response{}
+	}
+	ExpectWithOffset(1, r.send("PUT", "/responses/"+string(operation), responses)).To(Succeed())
+}
+
+func (r *Remote) Reset() {
+	ExpectWithOffset(1, r.send("DELETE", "", nil)).To(Succeed())
+}
+
+func (r *Remote) send(method, path string, value interface{}) error {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(method, r.URL+configPrefix+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	response, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		message, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("%s %s: %d %s", method, req.URL, response.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package fakebroker_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakebroker"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Remote", func() {
+	var broker *fakebroker.Broker
+	var server *httptest.Server
+	var remote *fakebroker.Remote
+
+	BeforeEach(func() {
+		broker = fakebroker.New(fakebroker.DefaultCatalog())
+		server = httptest.NewServer(broker)
+		remote = fakebroker.NewRemote(server.URL+"/", false)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("changes the catalog", func() {
+		catalog := fakebroker.DefaultCatalog()
+		catalog.Services[0].Plans = append(catalog.Services[0].Plans, fakebroker.Plan{ID: "paid-id", Name: "paid"})
+
+		remote.SetCatalog(catalog)
+		Expect(broker.Catalog()).To(Equal(catalog))
+	})
+
+	It("scripts responses", func() {
+		remote.Script(fakebroker.LastOperation, fakebroker.State("in progress", ""), fakebroker.Gone())
+
+		response, err := http.Get(server.URL + "/v2/service_instances/instance-1/last_operation")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+
+		response, err = http.Get(server.URL + "/v2/service_instances/instance-1/last_operation")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusGone))
+
+		remote.Script(fakebroker.LastOperation)
+		response, err = http.Get(server.URL + "/v2/service_instances/instance-1/last_operation")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("reads the journal", func() {
+		req, err := http.NewRequest("PUT", server.URL+"/v2/service_instances/instance-1/service_bindings/binding-1", strings.NewReader(`{"app_guid":"app-1"}`))
+		Expect(err).NotTo(HaveOccurred())
+		req.SetBasicAuth("username", "password")
+		_, err = http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		journal := remote.Journal()
+		Expect(journal).To(HaveLen(1))
+		Expect(journal[0].Operation).To(Equal(fakebroker.Bind))
+		Expect(journal[0].BindingID).To(Equal("binding-1"))
+
+		var details fakebroker.BindDetails
+		Expect(journal[0].DecodeBody(&details)).To(Succeed())
+		Expect(details.AppGUID).To(Equal("app-1"))
+
+		username, password, ok := journal[0].Credentials()
+		Expect(ok).To(BeTrue())
+		Expect(username).To(Equal("username"))
+		Expect(password).To(Equal("password"))
+	})
+
+	It("classifies the requests journaled by the broker assets", func() {
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			Expect(req.URL.Path).To(Equal("/journal"))
+			fmt.Fprint(w, `[{
+				"method": "DELETE",
+				"path": "/v2/service_instances/instance-1/service_bindings/binding-1",
+				"query": "service_id=service-1&plan_id=plan-1",
+				"header": {"X-Broker-Api-Version": ["2.5"]},
+				"body": "",
+				"received_at": "2015-06-01T12:00:00.123456789Z"
+			}]`)
+		})
+
+		journal := remote.Journal()
+		Expect(journal).To(HaveLen(1))
+		Expect(journal[0].Operation).To(Equal(fakebroker.Unbind))
+		Expect(journal[0].InstanceID).To(Equal("instance-1"))
+		Expect(journal[0].BindingID).To(Equal("binding-1"))
+		Expect(journal[0].QueryParam("plan_id")).To(Equal("plan-1"))
+		Expect(journal[0].Header.Get("X-Broker-Api-Version")).To(Equal("2.5"))
+	})
+
+	It("resets the broker without journaling its own requests", func() {
+		http.Get(server.URL + "/v2/catalog")
+		Expect(broker.Journal()).To(HaveLen(1))
+
+		remote.Reset()
+		remote.Script(fakebroker.Provision, fakebroker.Async())
+		Expect(broker.Journal()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("BuildApp", func() {
+	It("builds an app the binary buildpack can run", func() {
+		dir := fakebroker.BuildApp()
+		defer os.RemoveAll(dir)
+
+		_, err := os.Stat(filepath.Join(dir, "fake_service_broker"))
+		Expect(err).NotTo(HaveOccurred())
+
+		procfile, err := ioutil.ReadFile(filepath.Join(dir, "Procfile"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(procfile)).To(Equal("web: ./fake_service_broker\n"))
+	})
+})
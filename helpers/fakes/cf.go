@@ -0,0 +1,228 @@
+package fakes
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/runner"
+)
+
+const fakeCFPackage = "github.com/cloudfoundry/cf-acceptance-tests/helpers/fakes/fake_cf"
+
+// Invocation is a single command run through runner.Run while the fake is installed.
+type Invocation struct {
+	Executable string   `json:"executable"`
+	Args       []string `json:"args"`
+	CFHome     string   `json:"cf_home"`
+}
+
+func (i Invocation) String() string {
+	return strings.Join(append([]string{i.Executable}, i.Args...), " ")
+}
+
+type Response struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exit_code"`
+}
+
+type Handler func(Invocation) Response
+
+// Output returns a handler that prints stdout and exits successfully.
+func Output(stdout string) Handler {
+	return func(Invocation) Response {
+		return Response{Stdout: stdout}
+	}
+}
+
+// Failure returns a handler that prints stdout and exits with exitCode.
+func Failure(exitCode int, stdout string) Handler {
+	return func(Invocation) Response {
+		return Response{Stdout: stdout, ExitCode: exitCode}
+	}
+}
+
+// CF replaces every executable started through runner.Run (cf, curl, ...)
+// with the fake_cf binary, which hands the invocation back to this process.
+// Invocations are recorded and answered by the registered handlers; `cf curl`
+// is forwarded to CloudController when one is set.
+type CF struct {
+	CloudController *CloudController
+
+	binaryPath string
+	server     *httptest.Server
+
+	lock        sync.Mutex
+	invocations []Invocation
+	handlers    map[string]Handler
+
+	originalInterceptor func(*exec.Cmd) *exec.Cmd
+}
+
+// BuildCF compiles the fake_cf binary. Call it once per suite, e.g. in a
+// BeforeSuite, together with gexec.CleanupBuildArtifacts in the AfterSuite.
+func BuildCF() string {
+	path, err := gexec.Build(fakeCFPackage)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	return path
+}
+
+func NewCF(binaryPath string) *CF {
+	fake := &CF{
+		binaryPath: binaryPath,
+		handlers:   map[string]Handler{},
+	}
+	fake.server = httptest.NewServer(http.HandlerFunc(fake.serveInvocation))
+	return fake
+}
+
+// Install routes runner.Run through the fake until Uninstall is called.
+func (f *CF) Install() {
+	f.originalInterceptor = runner.CommandInterceptor
+	runner.CommandInterceptor = f.intercept
+}
+
+func (f *CF) Uninstall() {
+	if f.originalInterceptor != nil {
+		runner.CommandInterceptor = f.originalInterceptor
+		f.originalInterceptor = nil
+	}
+	f.server.Close()
+}
+
+// Handle registers a handler for a command, given as the executable optionally
+// followed by its first argument, e.g. "cf push" or "curl". The most specific
+// handler wins.
+func (f *CF) Handle(command string, handler Handler) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.handlers[command] = handler
+}
+
+func (f *CF) Invocations() []Invocation {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return append([]Invocation{}, f.invocations...)
+}
+
+// InvocationsOf returns the recorded invocations matching command, given in the
+// same form as for Handle.
+func (f *CF) InvocationsOf(command string) []Invocation {
+	matching := []Invocation{}
+	for _, invocation := range f.Invocations() {
+		if invocation.matches(command) {
+			matching = append(matching, invocation)
+		}
+	}
+	return matching
+}
+
+func (i Invocation) matches(command string) bool {
+	words := strings.Fields(command)
+	if len(words) == 0 || words[0] != i.Executable {
+		return false
+	}
+	if len(words) > 1 {
+		return len(i.Args) > 0 && i.Args[0] == words[1]
+	}
+	return true
+}
+
+func (f *CF) intercept(cmd *exec.Cmd) *exec.Cmd {
+	fakeCmd := exec.Command(f.binaryPath, cmd.Args[1:]...)
+	fakeCmd.Env = append(os.Environ(),
+		"FAKE_CF_URL="+f.server.URL,
+		"FAKE_CF_EXECUTABLE="+filepath.Base(cmd.Args[0]),
+	)
+	fakeCmd.Dir = cmd.Dir
+	return fakeCmd
+}
+
+func (f *CF) serveInvocation(w http.ResponseWriter, req *http.Request) {
+	var invocation Invocation
+	err := json.NewDecoder(req.Body).Decode(&invocation)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.lock.Lock()
+	f.invocations = append(f.invocations, invocation)
+	handler := f.handlerFor(invocation)
+	f.lock.Unlock()
+
+	json.NewEncoder(w).Encode(handler(invocation))
+}
+
+func (f *CF) handlerFor(invocation Invocation) Handler {
+	if len(invocation.Args) > 0 {
+		if handler, ok := f.handlers[invocation.Executable+" "+invocation.Args[0]]; ok {
+			return handler
+		}
+	}
+	if handler, ok := f.handlers[invocation.Executable]; ok {
+		return handler
+	}
+	if f.CloudController != nil && invocation.matches("cf curl") {
+		return f.forwardCurl
+	}
+	return Output("")
+}
+
+// forwardCurl answers `cf curl` the way the real CLI does: the response body
+// goes to stdout and the command succeeds regardless of the status code.
+func (f *CF) forwardCurl(invocation Invocation) Response {
+	args := invocation.Args[1:]
+	if len(args) == 0 {
+		return Response{Stderr: "Incorrect Usage.", ExitCode: 1}
+	}
+
+	endpoint := args[0]
+	method := "GET"
+	headers := http.Header{}
+	body := ""
+	for i := 1; i+1 < len(args); i += 2 {
+		switch args[i] {
+		case "-X":
+			method = args[i+1]
+		case "-d":
+			body = args[i+1]
+		case "-H":
+			parts := strings.SplitN(args[i+1], ":", 2)
+			if len(parts) == 2 {
+				headers.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+			}
+		}
+	}
+
+	request, err := http.NewRequest(method, f.CloudController.URL()+endpoint, strings.NewReader(body))
+	if err != nil {
+		return Response{Stderr: err.Error(), ExitCode: 1}
+	}
+	request.Header = headers
+	request.Header.Set("Authorization", "bearer "+FakeAccessToken)
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return Response{Stderr: err.Error(), ExitCode: 1}
+	}
+	defer response.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return Response{Stderr: err.Error(), ExitCode: 1}
+	}
+	return Response{Stdout: string(responseBody)}
+}
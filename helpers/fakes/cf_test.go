@@ -0,0 +1,107 @@
+package fakes_test
+
+import (
+	"os/exec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/runner"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("CF", func() {
+	var fakeCF *fakes.CF
+
+	BeforeEach(func() {
+		fakeCF = fakes.NewCF(fakeCFPath)
+		fakeCF.Install()
+	})
+
+	AfterEach(func() {
+		fakeCF.Uninstall()
+	})
+
+	It("records every invocation", func() {
+		Expect(cf.Cf("apps").Wait()).To(Exit(0))
+		Expect(runner.Curl("http://example.com").Wait()).To(Exit(0))
+
+		invocations := fakeCF.Invocations()
+		Expect(invocations).To(HaveLen(2))
+		Expect(invocations[0].Executable).To(Equal("cf"))
+		Expect(invocations[0].Args).To(Equal([]string{"apps"}))
+		Expect(invocations[1].Executable).To(Equal("curl"))
+		Expect(invocations[1].Args).To(Equal([]string{"-s", "http://example.com"}))
+	})
+
+	It("records the CF_HOME each command ran with", func() {
+		user := cf.NewUserContext("http://api.example.com", "user", "password", "org", "space", false)
+		cf.AsUser(user, func() {})
+
+		invocations := fakeCF.InvocationsOf("cf auth")
+		Expect(invocations).To(HaveLen(1))
+		Expect(invocations[0].CFHome).NotTo(BeEmpty())
+	})
+
+	It("replays the scripted output and exit code", func() {
+		fakeCF.Handle("cf push", fakes.Failure(1, "FAILED\nApp my-app failed to stage"))
+
+		push := cf.Cf("push", "my-app").Wait()
+		Expect(push).To(Exit(1))
+		Expect(push).To(Say("failed to stage"))
+	})
+
+	It("prefers the most specific handler", func() {
+		fakeCF.Handle("cf", fakes.Output("generic"))
+		fakeCF.Handle("cf apps", fakes.Output("specific"))
+
+		Expect(cf.Cf("apps").Wait()).To(Say("specific"))
+		Expect(cf.Cf("spaces").Wait()).To(Say("generic"))
+	})
+
+	Describe("cf curl", func() {
+		var cloudController *fakes.CloudController
+
+		BeforeEach(func() {
+			cloudController = fakes.NewCloudController()
+			fakeCF.CloudController = cloudController
+		})
+
+		AfterEach(func() {
+			cloudController.Close()
+		})
+
+		It("is answered by the fake cloud controller", func() {
+			cloudController.RouteToJSON("PUT", "/v2/apps/some-guid", 201, map[string]string{"name": "my-app"})
+
+			curl := cf.Cf("curl", "/v2/apps/some-guid", "-X", "PUT", "-d", `{"name":"my-app"}`).Wait()
+			Expect(curl).To(Exit(0))
+			Expect(curl.Out.Contents()).To(MatchJSON(`{"name":"my-app"}`))
+
+			requests := cloudController.ReceivedRequests()
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Method).To(Equal("PUT"))
+		})
+
+		It("succeeds even when the cloud controller returns an error", func() {
+			cloudController.RouteToError("GET", "/v2/apps/missing", 404, "CF-AppNotFound", "The app could not be found: missing")
+
+			curl := cf.Cf("curl", "/v2/apps/missing").Wait()
+			Expect(curl).To(Exit(0))
+			Expect(curl).To(Say("CF-AppNotFound"))
+		})
+	})
+
+	It("restores the original command interceptor when uninstalled", func() {
+		fakeCF.Uninstall()
+
+		cmd := exec.Command("cf", "apps")
+		Expect(runner.CommandInterceptor(cmd).Path).To(Equal(cmd.Path))
+
+		fakeCF = fakes.NewCF(fakeCFPath)
+		fakeCF.Install()
+	})
+})
@@ -0,0 +1,148 @@
+package fakes
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/onsi/gomega/ghttp"
+)
+
+const FakeAccessToken = "fake-access-token"
+
+// CloudController is an in-process stand-in for the Cloud Controller and UAA.
+// It answers /v2/info and the UAA token endpoint out of the box; everything
+// else has to be routed explicitly, and unrouted requests fail the spec.
+type CloudController struct {
+	*ghttp.Server
+}
+
+func NewCloudController() *CloudController {
+	cc := &CloudController{Server: ghttp.NewServer()}
+
+	cc.RouteToHandler("GET", "/v2/info", func(w http.ResponseWriter, req *http.Request) {
+		ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+			"name":                   "fake-cloud-controller",
+			"api_version":            "2.25.0",
+			"authorization_endpoint": cc.URL(),
+			"token_endpoint":         cc.URL(),
+			"logging_endpoint":       "wss://loggregator.example.com:443",
+		})(w, req)
+	})
+
+	cc.RouteToHandler("POST", "/oauth/token", ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+		"access_token":  FakeAccessToken,
+		"refresh_token": "fake-refresh-token",
+		"token_type":    "bearer",
+		"expires_in":    599,
+		"scope":         "cloud_controller.read cloud_controller.write openid",
+	}))
+
+	return cc
+}
+
+func (cc *CloudController) RouteToJSON(method, path string, statusCode int, body interface{}) {
+	cc.RouteToHandler(method, path, ghttp.RespondWithJSONEncoded(statusCode, body))
+}
+
+// RouteToError answers requests with a v2 style error body.
+func (cc *CloudController) RouteToError(method, path string, statusCode int, errorCode, description string) {
+	cc.RouteToJSON(method, path, statusCode, map[string]interface{}{
+		"code":        10000 + statusCode,
+		"error_code":  errorCode,
+		"description": description,
+	})
+}
+
+// RouteToPages serves a v2 list endpoint. The n-th page is returned for
+// ?page=n and links to the next one through next_url.
+func (cc *CloudController) RouteToPages(path string, pages ...[]interface{}) {
+	cc.RouteToHandler("GET", path, func(w http.ResponseWriter, req *http.Request) {
+		page, ok := cc.requestedPage(w, req, len(pages))
+		if !ok {
+			return
+		}
+
+		var nextUrl interface{}
+		if page < len(pages) {
+			nextUrl = pageUrl(req.URL, page+1)
+		}
+
+		ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+			"total_results": countResources(pages),
+			"total_pages":   len(pages),
+			"next_url":      nextUrl,
+			"resources":     pageResources(pages, page),
+		})(w, req)
+	})
+}
+
+// RouteToV3Pages is the v3 equivalent of RouteToPages, linking the pages
+// through pagination.next, whose href is absolute as on a real Cloud
+// Controller.
+func (cc *CloudController) RouteToV3Pages(path string, pages ...[]interface{}) {
+	cc.RouteToHandler("GET", path, func(w http.ResponseWriter, req *http.Request) {
+		page, ok := cc.requestedPage(w, req, len(pages))
+		if !ok {
+			return
+		}
+
+		var next interface{}
+		if page < len(pages) {
+			next = map[string]string{"href": cc.URL() + pageUrl(req.URL, page+1)}
+		}
+
+		ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+			"pagination": map[string]interface{}{
+				"total_results": countResources(pages),
+				"next":          next,
+			},
+			"resources": pageResources(pages, page),
+		})(w, req)
+	})
+}
+
+func (cc *CloudController) requestedPage(w http.ResponseWriter, req *http.Request, pageCount int) (int, bool) {
+	requested := req.URL.Query().Get("page")
+	if requested == "" {
+		return 1, true
+	}
+
+	page, err := strconv.Atoi(requested)
+	if err != nil || page < 1 || page > pageCount {
+		cc.respondWithError(w, req, http.StatusBadRequest, fmt.Sprintf("invalid page '%s'", requested))
+		return 0, false
+	}
+	return page, true
+}
+
+func (cc *CloudController) respondWithError(w http.ResponseWriter, req *http.Request, statusCode int, description string) {
+	ghttp.RespondWithJSONEncoded(statusCode, map[string]interface{}{
+		"code":        10000 + statusCode,
+		"error_code":  "CF-InvalidRequest",
+		"description": description,
+	})(w, req)
+}
+
+func countResources(pages [][]interface{}) int {
+	total := 0
+	for _, resources := range pages {
+		total += len(resources)
+	}
+	return total
+}
+
+func pageResources(pages [][]interface{}, page int) []interface{} {
+	resources := []interface{}{}
+	if len(pages) > 0 {
+		resources = append(resources, pages[page-1]...)
+	}
+	return resources
+}
+
+func pageUrl(requestUrl *url.URL, page int) string {
+	query := requestUrl.Query()
+	query.Set("page", strconv.Itoa(page))
+	return requestUrl.Path + "?" + query.Encode()
+}
@@ -0,0 +1,60 @@
+// fake_cf stands in for cf, curl and any other executable intercepted by
+// fakes.CF. It forwards its arguments to the test process named by
+// $FAKE_CF_URL and replays the response it gets back.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+type invocation struct {
+	Executable string   `json:"executable"`
+	Args       []string `json:"args"`
+	CFHome     string   `json:"cf_home"`
+}
+
+type response struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exit_code"`
+}
+
+func main() {
+	url := os.Getenv("FAKE_CF_URL")
+	if url == "" {
+		fmt.Fprintln(os.Stderr, "fake_cf: $FAKE_CF_URL is not set")
+		os.Exit(127)
+	}
+
+	body, err := json.Marshal(invocation{
+		Executable: os.Getenv("FAKE_CF_EXECUTABLE"),
+		Args:       os.Args[1:],
+		CFHome:     os.Getenv("CF_HOME"),
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fake_cf:", err)
+		os.Exit(127)
+	}
+
+	httpResponse, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fake_cf:", err)
+		os.Exit(127)
+	}
+	defer httpResponse.Body.Close()
+
+	var result response
+	err = json.NewDecoder(httpResponse.Body).Decode(&result)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fake_cf: invalid response:", err)
+		os.Exit(127)
+	}
+
+	fmt.Fprint(os.Stdout, result.Stdout)
+	fmt.Fprint(os.Stderr, result.Stderr)
+	os.Exit(result.ExitCode)
+}
@@ -0,0 +1,26 @@
+package fakes_test
+
+import (
+	"testing"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+)
+
+var fakeCFPath string
+
+var _ = BeforeSuite(func() {
+	fakeCFPath = fakes.BuildCF()
+})
+
+var _ = AfterSuite(func() {
+	gexec.CleanupBuildArtifacts()
+})
+
+func TestFakes(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fakes Suite")
+}
@@ -0,0 +1,53 @@
+package fakes
+
+import (
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+)
+
+// Suite is the fake CF, and the CloudController behind its `cf curl`, that
+// InstallForSuite puts in place for the running spec.
+type Suite struct {
+	CF              *CF
+	CloudController *CloudController
+}
+
+// InstallForSuite builds fake_cf once per suite and installs a fresh CF and
+// CloudController around every spec. It registers Ginkgo nodes, so call it at
+// the top level of a suite file.
+func InstallForSuite() *Suite {
+	suite := &Suite{}
+	var binaryPath string
+
+	BeforeSuite(func() {
+		binaryPath = BuildCF()
+	})
+
+	AfterSuite(func() {
+		gexec.CleanupBuildArtifacts()
+	})
+
+	BeforeEach(func() {
+		suite.CloudController = NewCloudController()
+		suite.CF = NewCF(binaryPath)
+		suite.CF.CloudController = suite.CloudController
+		suite.CF.Install()
+	})
+
+	AfterEach(func() {
+		suite.CF.Uninstall()
+		suite.CloudController.Close()
+	})
+
+	return suite
+}
+
+// InstallTracker installs a cleanup tracker that removes resources as a fake
+// admin user, for suites whose helpers record in cleanup.Current(). Call it
+// before InstallForSuite so the tracker cleans up while the fake is installed.
+func InstallTracker() *cleanup.Tracker {
+	return cleanup.Install(cf.NewUserContext("api.example.com", "admin", "secret", "", "", false))
+}
@@ -0,0 +1,164 @@
+package janitor
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// client talks to the Cloud Controller and UAA over HTTP as the admin user.
+// The janitor runs outside of any suite, so it can't rely on a logged in cf.
+type client struct {
+	api        string
+	uaa        string
+	token      string
+	httpClient *http.Client
+}
+
+type info struct {
+	TokenEndpoint string `json:"token_endpoint"`
+}
+
+type resource struct {
+	Metadata struct {
+		Guid      string `json:"guid"`
+		CreatedAt string `json:"created_at"`
+	} `json:"metadata"`
+	Entity struct {
+		Name     string `json:"name"`
+		Username string `json:"username"`
+	} `json:"entity"`
+}
+
+type page struct {
+	NextUrl   string     `json:"next_url"`
+	Resources []resource `json:"resources"`
+}
+
+func login(api, username, password string, skipSSLValidation bool) (*client, error) {
+	if !strings.HasPrefix(api, "http://") && !strings.HasPrefix(api, "https://") {
+		api = "https://" + api
+	}
+
+	c := &client{
+		api: strings.TrimRight(api, "/"),
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: skipSSLValidation},
+			},
+		},
+	}
+
+	var apiInfo info
+	err := c.do("GET", c.api+"/v2/info", nil, &apiInfo)
+	if err != nil {
+		return nil, err
+	}
+	c.uaa = strings.TrimRight(apiInfo.TokenEndpoint, "/")
+
+	form := url.Values{
+		"grant_type": {"password"},
+		"username":   {username},
+		"password":   {password},
+	}
+	request, err := http.NewRequest("POST", c.uaa+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.SetBasicAuth("cf", "")
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+	}
+	err = c.send(request, &token)
+	if err != nil {
+		return nil, fmt.Errorf("logging in as %s: %s", username, err)
+	}
+	c.token = "bearer " + token.AccessToken
+	return c, nil
+}
+
+// list returns every resource of a v2 list endpoint, following next_url.
+func (c *client) list(path string) ([]resource, error) {
+	resources := []resource{}
+	for path != "" {
+		var p page
+		err := c.do("GET", c.api+path, nil, &p)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, p.Resources...)
+		path = p.NextUrl
+	}
+	return resources, nil
+}
+
+// delete deletes a resource, ignoring that it is already gone.
+func (c *client) delete(endpoint string) error {
+	err := c.do("DELETE", endpoint, nil, nil)
+	if statusErr, ok := err.(*statusError); ok && statusErr.statusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+func (c *client) do(method, endpoint string, body io.Reader, response interface{}) error {
+	request, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		request.Header.Set("Authorization", c.token)
+	}
+	return c.send(request, response)
+}
+
+type statusError struct {
+	method     string
+	url        string
+	statusCode int
+	body       string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s %s failed with status %d: %s", e.method, e.url, e.statusCode, e.body)
+}
+
+func (c *client) send(request *http.Request, response interface{}) error {
+	httpResponse, err := c.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %s", request.Method, request.URL, err)
+	}
+	defer httpResponse.Body.Close()
+
+	body, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return err
+	}
+
+	if httpResponse.StatusCode >= 300 {
+		return &statusError{
+			method:     request.Method,
+			url:        request.URL.String(),
+			statusCode: httpResponse.StatusCode,
+			body:       strings.TrimSpace(string(body)),
+		}
+	}
+
+	if response == nil || len(body) == 0 {
+		return nil
+	}
+	err = json.Unmarshal(body, response)
+	if err != nil {
+		return fmt.Errorf("%s %s returned an invalid response: %s", request.Method, request.URL, err)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+// Package janitor finds and deletes what aborted CATS runs leave behind: the
+// orgs, quotas and users of ConfiguredContext, and the security groups,
+// buildpacks and service brokers that specs register platform-wide.
+package janitor
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// DefaultMaxAge is comfortably longer than a full CATS run.
+const DefaultMaxAge = 24 * time.Hour
+
+// Kind is a type of entity the janitor cleans up.
+type Kind struct {
+	Name   string
+	Prefix string
+
+	// Path lists the entities on the Cloud Controller.
+	Path string
+
+	// nameOf returns the name an entity is matched by.
+	nameOf func(resource) string
+
+	// remove deletes one entity.
+	remove func(c *client, guid string) error
+}
+
+// kinds are cleaned up in this order, so that e.g. orgs are gone before the
+// quotas they use.
+var kinds = []Kind{
+	{
+		Name:   "security group",
+		Prefix: "CATS-SG-",
+		Path:   "/v2/security_groups",
+		nameOf: byName,
+		remove: deleteV2("/v2/security_groups/%s"),
+	},
+	{
+		Name:   "buildpack",
+		Prefix: "CATS-SGBP-",
+		Path:   "/v2/buildpacks",
+		nameOf: byName,
+		remove: deleteV2("/v2/buildpacks/%s"),
+	},
+	{
+		Name:   "service broker",
+		Prefix: "CATS-BROKER-",
+		Path:   "/v2/service_brokers",
+		nameOf: byName,
+		remove: deleteServiceBroker,
+	},
+	{
+		Name:   "org",
+		Prefix: "CATS-ORG-",
+		Path:   "/v2/organizations",
+		nameOf: byName,
+		remove: deleteV2("/v2/organizations/%s?recursive=true&async=false"),
+	},
+	{
+		Name:   "quota",
+		Prefix: "CATS-QUOTA-",
+		Path:   "/v2/quota_definitions",
+		nameOf: byName,
+		remove: deleteV2("/v2/quota_definitions/%s"),
+	},
+	{
+		Name:   "user",
+		Prefix: "CATS-USER-",
+		Path:   "/v2/users",
+		nameOf: func(r resource) string { return r.Entity.Username },
+		remove: deleteUser,
+	},
+}
+
+// Orphan is an entity left behind by an earlier run.
+type Orphan struct {
+	Kind      Kind
+	Name      string
+	Guid      string
+	CreatedAt time.Time
+}
+
+func (o Orphan) String() string {
+	return fmt.Sprintf("%s %s (%s, created %s)", o.Kind.Name, o.Name, o.Guid, o.CreatedAt.Format(time.RFC3339))
+}
+
+// Janitor deletes orphans older than MaxAge. Younger entities may belong to
+// a run that is still going.
+type Janitor struct {
+	Api               string
+	AdminUser         string
+	AdminPassword     string
+	SkipSSLValidation bool
+
+	MaxAge time.Duration
+	DryRun bool
+
+	// Out receives a line per orphan found and deleted.
+	Out io.Writer
+
+	// Now is the time ages are measured from; time.Now when nil.
+	Now func() time.Time
+}
+
+// Errors collects every deletion that failed.
+type Errors []error
+
+func (e Errors) Error() string {
+	messages := []string{}
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Run logs in, finds the orphans and deletes them, unless DryRun is set. It
+// carries on past failed deletions and returns them together.
+func (j *Janitor) Run() ([]Orphan, error) {
+	c, err := login(j.Api, j.AdminUser, j.AdminPassword, j.SkipSSLValidation)
+	if err != nil {
+		return nil, err
+	}
+
+	orphans, err := j.find(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs Errors
+	for _, orphan := range orphans {
+		if j.DryRun {
+			j.printf("would delete %s\n", orphan)
+			continue
+		}
+
+		err := orphan.Kind.remove(c, orphan.Guid)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("deleting %s: %s", orphan, err))
+			continue
+		}
+		j.printf("deleted %s\n", orphan)
+	}
+
+	if len(errs) > 0 {
+		return orphans, errs
+	}
+	return orphans, nil
+}
+
+func (j *Janitor) find(c *client) ([]Orphan, error) {
+	now := time.Now
+	if j.Now != nil {
+		now = j.Now
+	}
+	cutoff := now().Add(-j.MaxAge)
+
+	orphans := []Orphan{}
+	for _, kind := range kinds {
+		resources, err := c.list(kind.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range resources {
+			name := kind.nameOf(r)
+			if !strings.HasPrefix(name, kind.Prefix) {
+				continue
+			}
+
+			createdAt, err := time.Parse(time.RFC3339, r.Metadata.CreatedAt)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s has an invalid created_at: %s", kind.Name, name, err)
+			}
+			if createdAt.After(cutoff) {
+				continue
+			}
+
+			orphans = append(orphans, Orphan{Kind: kind, Name: name, Guid: r.Metadata.Guid, CreatedAt: createdAt})
+		}
+	}
+	return orphans, nil
+}
+
+func (j *Janitor) printf(format string, args ...interface{}) {
+	out := j.Out
+	if out == nil {
+		out = ioutil.Discard
+	}
+	fmt.Fprintf(out, format, args...)
+}
+
+func byName(r resource) string {
+	return r.Entity.Name
+}
+
+func deleteV2(pathFormat string) func(*client, string) error {
+	return func(c *client, guid string) error {
+		return c.delete(c.api + fmt.Sprintf(pathFormat, guid))
+	}
+}
+
+// deleteServiceBroker purges the broker's services first, since a broker
+// can't be deleted while instances of its services exist and the broker app
+// that would deprovision them is usually long gone.
+func deleteServiceBroker(c *client, guid string) error {
+	services, err := c.list("/v2/services?q=service_broker_guid:" + guid)
+	if err != nil {
+		return err
+	}
+
+	for _, service := range services {
+		err := c.delete(fmt.Sprintf("%s/v2/services/%s?purge=true", c.api, service.Metadata.Guid))
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.delete(fmt.Sprintf("%s/v2/service_brokers/%s", c.api, guid))
+}
+
+// deleteUser removes the user from the Cloud Controller and from UAA, as
+// `cf delete-user` does.
+func deleteUser(c *client, guid string) error {
+	err := c.delete(fmt.Sprintf("%s/v2/users/%s", c.api, guid))
+	if err != nil {
+		return err
+	}
+	return c.delete(fmt.Sprintf("%s/Users/%s", c.uaa, guid))
+}
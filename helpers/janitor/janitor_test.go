@@ -0,0 +1,195 @@
+package janitor_test
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakes"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/janitor"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Janitor", func() {
+	var (
+		cloudController *fakes.CloudController
+		out             *bytes.Buffer
+		j               *janitor.Janitor
+
+		lock          sync.Mutex
+		deleted       []string
+		authorization []string
+
+		now    = time.Date(2016, 3, 10, 12, 0, 0, 0, time.UTC)
+		old    = now.Add(-48 * time.Hour).Format(time.RFC3339)
+		recent = now.Add(-time.Hour).Format(time.RFC3339)
+	)
+
+	resource := func(guid, name, createdAt string) interface{} {
+		return map[string]interface{}{
+			"metadata": map[string]interface{}{"guid": guid, "created_at": createdAt},
+			"entity":   map[string]interface{}{"name": name},
+		}
+	}
+
+	user := func(guid, username, createdAt string) interface{} {
+		return map[string]interface{}{
+			"metadata": map[string]interface{}{"guid": guid, "created_at": createdAt},
+			"entity":   map[string]interface{}{"username": username},
+		}
+	}
+
+	recordDeletes := func(path string, statusCode int) {
+		cloudController.RouteToHandler("DELETE", path, func(w http.ResponseWriter, req *http.Request) {
+			lock.Lock()
+			deleted = append(deleted, req.URL.RequestURI())
+			authorization = append(authorization, req.Header.Get("Authorization"))
+			lock.Unlock()
+			w.WriteHeader(statusCode)
+		})
+	}
+
+	BeforeEach(func() {
+		cloudController = fakes.NewCloudController()
+		out = &bytes.Buffer{}
+		deleted = nil
+		authorization = nil
+
+		for _, path := range []string{
+			"/v2/security_groups", "/v2/buildpacks", "/v2/service_brokers",
+			"/v2/organizations", "/v2/quota_definitions", "/v2/users",
+		} {
+			cloudController.RouteToPages(path)
+		}
+
+		j = &janitor.Janitor{
+			Api:           cloudController.URL(),
+			AdminUser:     "admin",
+			AdminPassword: "admin-password",
+			MaxAge:        24 * time.Hour,
+			Out:           out,
+			Now:           func() time.Time { return now },
+		}
+	})
+
+	AfterEach(func() {
+		cloudController.Close()
+	})
+
+	It("deletes prefixed entities older than the max age", func() {
+		cloudController.RouteToPages("/v2/organizations",
+			[]interface{}{
+				resource("old-org", "CATS-ORG-1-2016_03_08-09h00m00.000s", old),
+				resource("new-org", "CATS-ORG-2-2016_03_10-11h00m00.000s", recent),
+			},
+			[]interface{}{
+				resource("persistent-org", "CATS-persistent-org", old),
+				resource("other-org", "my-org", old),
+			},
+		)
+		cloudController.RouteToPages("/v2/quota_definitions",
+			[]interface{}{resource("old-quota", "CATS-QUOTA-1-2016_03_08-09h00m00.000s", old)},
+		)
+		recordDeletes("/v2/organizations/old-org", http.StatusNoContent)
+		recordDeletes("/v2/quota_definitions/old-quota", http.StatusNoContent)
+
+		orphans, err := j.Run()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(orphans).To(HaveLen(2))
+		Expect(orphans[0].Name).To(Equal("CATS-ORG-1-2016_03_08-09h00m00.000s"))
+		Expect(orphans[1].Name).To(Equal("CATS-QUOTA-1-2016_03_08-09h00m00.000s"))
+
+		Expect(deleted).To(Equal([]string{
+			"/v2/organizations/old-org?recursive=true&async=false",
+			"/v2/quota_definitions/old-quota",
+		}))
+		Expect(authorization).To(ConsistOf("bearer "+fakes.FakeAccessToken, "bearer "+fakes.FakeAccessToken))
+		Expect(out.String()).To(ContainSubstring("deleted org CATS-ORG-1-2016_03_08-09h00m00.000s (old-org"))
+	})
+
+	It("matches users by username and deletes them from UAA as well", func() {
+		cloudController.RouteToPages("/v2/users",
+			[]interface{}{user("old-user", "CATS-USER-1-2016_03_08-09h00m00.000s", old)},
+		)
+		recordDeletes("/v2/users/old-user", http.StatusNoContent)
+		recordDeletes("/Users/old-user", http.StatusNotFound)
+
+		_, err := j.Run()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(Equal([]string{"/v2/users/old-user", "/Users/old-user"}))
+	})
+
+	It("purges a broker's services before deleting the broker", func() {
+		cloudController.RouteToPages("/v2/service_brokers",
+			[]interface{}{resource("old-broker", "CATS-BROKER-abc", old)},
+		)
+		cloudController.RouteToPages("/v2/services", []interface{}{resource("old-service", "some-service", old)})
+		recordDeletes("/v2/services/old-service", http.StatusNoContent)
+		recordDeletes("/v2/service_brokers/old-broker", http.StatusNoContent)
+
+		_, err := j.Run()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(Equal([]string{
+			"/v2/services/old-service?purge=true",
+			"/v2/service_brokers/old-broker",
+		}))
+
+		var listedServices []string
+		for _, request := range cloudController.ReceivedRequests() {
+			if request.URL.Path == "/v2/services" {
+				listedServices = append(listedServices, request.URL.RawQuery)
+			}
+		}
+		Expect(listedServices).To(Equal([]string{"q=service_broker_guid:old-broker"}))
+	})
+
+	Context("in dry-run mode", func() {
+		BeforeEach(func() {
+			j.DryRun = true
+		})
+
+		It("reports the orphans without deleting them", func() {
+			cloudController.RouteToPages("/v2/buildpacks",
+				[]interface{}{resource("old-buildpack", "CATS-SGBP-abc", old)},
+			)
+
+			orphans, err := j.Run()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphans).To(HaveLen(1))
+			Expect(deleted).To(BeEmpty())
+			Expect(out.String()).To(ContainSubstring("would delete buildpack CATS-SGBP-abc"))
+		})
+	})
+
+	It("carries on past failed deletions and returns them together", func() {
+		cloudController.RouteToPages("/v2/security_groups",
+			[]interface{}{
+				resource("sg-1", "CATS-SG-1", old),
+				resource("sg-2", "CATS-SG-2", old),
+				resource("sg-3", "CATS-SG-3", old),
+			},
+		)
+		recordDeletes("/v2/security_groups/sg-1", http.StatusInternalServerError)
+		recordDeletes("/v2/security_groups/sg-2", http.StatusNoContent)
+		recordDeletes("/v2/security_groups/sg-3", http.StatusForbidden)
+
+		_, err := j.Run()
+		Expect(err).To(HaveOccurred())
+		Expect(err.(janitor.Errors)).To(HaveLen(2))
+		Expect(err.Error()).To(ContainSubstring("deleting security group CATS-SG-1"))
+		Expect(err.Error()).To(ContainSubstring("deleting security group CATS-SG-3"))
+		Expect(deleted).To(HaveLen(3))
+	})
+
+	It("fails when it can't log in", func() {
+		cloudController.RouteToError("POST", "/oauth/token", http.StatusUnauthorized, "unauthorized", "Bad credentials")
+
+		_, err := j.Run()
+		Expect(err).To(MatchError(ContainSubstring("logging in as admin")))
+		Expect(deleted).To(BeEmpty())
+	})
+})
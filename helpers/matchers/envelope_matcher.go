@@ -0,0 +1,187 @@
+package matchers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cloudfoundry/noaa/events"
+	"github.com/onsi/gomega/format"
+)
+
+// EnvelopeMatcher matches a firehose envelope against a set of conditions,
+// all of which must hold. Start with one of the Envelope* constructors and
+// chain further conditions:
+//
+//	Receive(EnvelopeOfType(events.Envelope_HttpStartStop).ForApp(appGuid).WithStatusCode(200))
+type EnvelopeMatcher struct {
+	conditions []envelopeCondition
+	failed     []string
+}
+
+type envelopeCondition struct {
+	description string
+	holds       func(*events.Envelope) bool
+}
+
+func EnvelopeOfType(eventType events.Envelope_EventType) *EnvelopeMatcher {
+	return new(EnvelopeMatcher).OfType(eventType)
+}
+
+func EnvelopeFromOrigin(origin string) *EnvelopeMatcher {
+	return new(EnvelopeMatcher).FromOrigin(origin)
+}
+
+func EnvelopeForApp(appGuid string) *EnvelopeMatcher {
+	return new(EnvelopeMatcher).ForApp(appGuid)
+}
+
+func EnvelopeWithSourceType(sourceType string) *EnvelopeMatcher {
+	return new(EnvelopeMatcher).WithSourceType(sourceType)
+}
+
+func EnvelopeWithLogMessageMatching(pattern string) *EnvelopeMatcher {
+	return new(EnvelopeMatcher).WithLogMessageMatching(pattern)
+}
+
+func EnvelopeWithValueMetric(name string) *EnvelopeMatcher {
+	return new(EnvelopeMatcher).WithValueMetric(name)
+}
+
+func EnvelopeWithCounterEvent(name string) *EnvelopeMatcher {
+	return new(EnvelopeMatcher).WithCounterEvent(name)
+}
+
+func EnvelopeWithContainerMetric(instanceIndex int) *EnvelopeMatcher {
+	return new(EnvelopeMatcher).WithContainerMetric(instanceIndex)
+}
+
+func EnvelopeWhere(description string, predicate func(*events.Envelope) bool) *EnvelopeMatcher {
+	return new(EnvelopeMatcher).Where(description, predicate)
+}
+
+func (matcher *EnvelopeMatcher) OfType(eventType events.Envelope_EventType) *EnvelopeMatcher {
+	return matcher.Where("event type "+eventType.String(), func(envelope *events.Envelope) bool {
+		return envelope.GetEventType() == eventType
+	})
+}
+
+func (matcher *EnvelopeMatcher) FromOrigin(origin string) *EnvelopeMatcher {
+	return matcher.Where("origin "+origin, func(envelope *events.Envelope) bool {
+		return envelope.GetOrigin() == origin
+	})
+}
+
+// ForApp matches log messages, HTTP events and container metrics that belong
+// to the app.
+func (matcher *EnvelopeMatcher) ForApp(appGuid string) *EnvelopeMatcher {
+	return matcher.Where("app "+appGuid, func(envelope *events.Envelope) bool {
+		return EnvelopeAppId(envelope) == appGuid
+	})
+}
+
+// WithSourceType matches log messages from a source such as "App", "RTR" or "STG".
+func (matcher *EnvelopeMatcher) WithSourceType(sourceType string) *EnvelopeMatcher {
+	return matcher.Where("source type "+sourceType, func(envelope *events.Envelope) bool {
+		return envelope.GetLogMessage() != nil && envelope.GetLogMessage().GetSourceType() == sourceType
+	})
+}
+
+func (matcher *EnvelopeMatcher) WithLogMessageMatching(pattern string) *EnvelopeMatcher {
+	expression := regexp.MustCompile(pattern)
+	return matcher.Where("log message matching /"+pattern+"/", func(envelope *events.Envelope) bool {
+		return envelope.GetLogMessage() != nil && expression.Match(envelope.GetLogMessage().GetMessage())
+	})
+}
+
+func (matcher *EnvelopeMatcher) WithValueMetric(name string) *EnvelopeMatcher {
+	return matcher.Where("value metric "+name, func(envelope *events.Envelope) bool {
+		return envelope.GetValueMetric() != nil && envelope.GetValueMetric().GetName() == name
+	})
+}
+
+func (matcher *EnvelopeMatcher) WithCounterEvent(name string) *EnvelopeMatcher {
+	return matcher.Where("counter event "+name, func(envelope *events.Envelope) bool {
+		return envelope.GetCounterEvent() != nil && envelope.GetCounterEvent().GetName() == name
+	})
+}
+
+// WithContainerMetric matches the container metrics of one of an app's instances.
+func (matcher *EnvelopeMatcher) WithContainerMetric(instanceIndex int) *EnvelopeMatcher {
+	return matcher.Where(fmt.Sprintf("container metric for instance %d", instanceIndex), func(envelope *events.Envelope) bool {
+		return envelope.GetContainerMetric() != nil && int(envelope.GetContainerMetric().GetInstanceIndex()) == instanceIndex
+	})
+}
+
+func (matcher *EnvelopeMatcher) WithStatusCode(statusCode int) *EnvelopeMatcher {
+	return matcher.Where(fmt.Sprintf("HTTP status code %d", statusCode), func(envelope *events.Envelope) bool {
+		return envelope.GetHttpStartStop() != nil && int(envelope.GetHttpStartStop().GetStatusCode()) == statusCode
+	})
+}
+
+// Where adds an arbitrary condition. The description is used in failure messages.
+func (matcher *EnvelopeMatcher) Where(description string, predicate func(*events.Envelope) bool) *EnvelopeMatcher {
+	matcher.conditions = append(matcher.conditions, envelopeCondition{description: description, holds: predicate})
+	return matcher
+}
+
+func (matcher *EnvelopeMatcher) Match(actual interface{}) (success bool, err error) {
+	envelope, ok := actual.(*events.Envelope)
+	if !ok {
+		return false, fmt.Errorf("EnvelopeMatcher matcher: actual value must be an events.Envelope")
+	}
+
+	matcher.failed = nil
+	for _, condition := range matcher.conditions {
+		if !condition.holds(envelope) {
+			matcher.failed = append(matcher.failed, condition.description)
+		}
+	}
+	return len(matcher.failed) == 0, nil
+}
+
+func (matcher *EnvelopeMatcher) FailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("Expected\n%s\nto have %s\nbut it did not have %s",
+		format.Object(actual, 1), matcher.description(), strings.Join(matcher.failed, ", "))
+}
+
+func (matcher *EnvelopeMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("Expected\n%s\nnot to have %s", format.Object(actual, 1), matcher.description())
+}
+
+func (matcher *EnvelopeMatcher) description() string {
+	descriptions := []string{}
+	for _, condition := range matcher.conditions {
+		descriptions = append(descriptions, condition.description)
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// EnvelopeAppId returns the app GUID carried by a log message, HTTP
+// start/stop event or container metric, or the empty string for other events.
+func EnvelopeAppId(envelope *events.Envelope) string {
+	if envelope.GetLogMessage() != nil {
+		return envelope.GetLogMessage().GetAppId()
+	}
+	if envelope.GetHttpStartStop() != nil {
+		return formatUUID(envelope.GetHttpStartStop().GetApplicationId())
+	}
+	if envelope.GetContainerMetric() != nil {
+		return envelope.GetContainerMetric().GetApplicationId()
+	}
+	return ""
+}
+
+// formatUUID reverses dropsonde's encoding of a UUID as two little-endian
+// uint64 halves.
+func formatUUID(uuid *events.UUID) string {
+	if uuid == nil {
+		return ""
+	}
+
+	var b [16]byte
+	binary.LittleEndian.PutUint64(b[:8], uuid.GetLow())
+	binary.LittleEndian.PutUint64(b[8:], uuid.GetHigh())
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
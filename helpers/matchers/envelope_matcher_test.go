@@ -0,0 +1,144 @@
+package matchers_test
+
+import (
+	"code.google.com/p/gogoprotobuf/proto"
+	"github.com/cloudfoundry/noaa/events"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/matchers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const appGuid = "5f7b3c1e-7d4a-4b6e-9a51-0c9f3f1e2d7a"
+
+var _ = Describe("EnvelopeMatcher", func() {
+	var logEnvelope, httpEnvelope, metricEnvelope, counterEnvelope, containerEnvelope *events.Envelope
+
+	BeforeEach(func() {
+		logEnvelope = &events.Envelope{
+			Origin:    proto.String("dea_logging_agent"),
+			EventType: events.Envelope_LogMessage.Enum(),
+			LogMessage: &events.LogMessage{
+				Message:        []byte("Muahaha... let's go"),
+				MessageType:    events.LogMessage_OUT.Enum(),
+				Timestamp:      proto.Int64(1),
+				AppId:          proto.String(appGuid),
+				SourceType:     proto.String("App"),
+				SourceInstance: proto.String("0"),
+			},
+		}
+
+		// 5f7b3c1e-7d4a-4b6e-9a51-0c9f3f1e2d7a as dropsonde encodes it
+		httpEnvelope = &events.Envelope{
+			Origin:    proto.String("gorouter"),
+			EventType: events.Envelope_HttpStartStop.Enum(),
+			HttpStartStop: &events.HttpStartStop{
+				StatusCode: proto.Int32(200),
+				ApplicationId: &events.UUID{
+					Low:  proto.Uint64(0x6e4b4a7d1e3c7b5f),
+					High: proto.Uint64(0x7a2d1e3f9f0c519a),
+				},
+			},
+		}
+
+		metricEnvelope = &events.Envelope{
+			Origin:      proto.String("DEA"),
+			EventType:   events.Envelope_ValueMetric.Enum(),
+			ValueMetric: &events.ValueMetric{Name: proto.String("memory"), Value: proto.Float64(1), Unit: proto.String("b")},
+		}
+
+		counterEnvelope = &events.Envelope{
+			Origin:       proto.String("router__0"),
+			EventType:    events.Envelope_CounterEvent.Enum(),
+			CounterEvent: &events.CounterEvent{Name: proto.String("requests"), Delta: proto.Uint64(1)},
+		}
+
+		containerEnvelope = &events.Envelope{
+			Origin:    proto.String("rep"),
+			EventType: events.Envelope_ContainerMetric.Enum(),
+			ContainerMetric: &events.ContainerMetric{
+				ApplicationId: proto.String(appGuid),
+				InstanceIndex: proto.Int32(1),
+				CpuPercentage: proto.Float64(0.5),
+				MemoryBytes:   proto.Uint64(1024),
+				DiskBytes:     proto.Uint64(2048),
+			},
+		}
+	})
+
+	It("matches on event type", func() {
+		Expect(logEnvelope).To(EnvelopeOfType(events.Envelope_LogMessage))
+		Expect(httpEnvelope).NotTo(EnvelopeOfType(events.Envelope_LogMessage))
+	})
+
+	It("matches on origin", func() {
+		Expect(httpEnvelope).To(EnvelopeFromOrigin("gorouter"))
+		Expect(logEnvelope).NotTo(EnvelopeFromOrigin("gorouter"))
+	})
+
+	It("matches log messages, HTTP events and container metrics by app GUID", func() {
+		Expect(logEnvelope).To(EnvelopeForApp(appGuid))
+		Expect(httpEnvelope).To(EnvelopeForApp(appGuid))
+		Expect(containerEnvelope).To(EnvelopeForApp(appGuid))
+		Expect(metricEnvelope).NotTo(EnvelopeForApp(appGuid))
+		Expect(EnvelopeAppId(httpEnvelope)).To(Equal(appGuid))
+	})
+
+	It("matches log messages by source type and text", func() {
+		Expect(logEnvelope).To(EnvelopeWithSourceType("App"))
+		Expect(logEnvelope).NotTo(EnvelopeWithSourceType("RTR"))
+		Expect(logEnvelope).To(EnvelopeWithLogMessageMatching(`^Muahaha\.+`))
+		Expect(metricEnvelope).NotTo(EnvelopeWithLogMessageMatching(`.*`))
+	})
+
+	It("matches metrics by name", func() {
+		Expect(metricEnvelope).To(EnvelopeWithValueMetric("memory"))
+		Expect(counterEnvelope).To(EnvelopeWithCounterEvent("requests"))
+		Expect(counterEnvelope).NotTo(EnvelopeWithValueMetric("requests"))
+	})
+
+	It("matches container metrics by instance", func() {
+		Expect(containerEnvelope).To(EnvelopeWithContainerMetric(1))
+		Expect(containerEnvelope).NotTo(EnvelopeWithContainerMetric(0))
+		Expect(metricEnvelope).NotTo(EnvelopeWithContainerMetric(1))
+	})
+
+	It("decodes container metrics from the firehose", func() {
+		data, err := proto.Marshal(containerEnvelope)
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded := &events.Envelope{}
+		Expect(proto.Unmarshal(data, decoded)).To(Succeed())
+		Expect(decoded).To(EnvelopeOfType(events.Envelope_ContainerMetric).ForApp(appGuid).WithContainerMetric(1))
+		Expect(decoded.GetContainerMetric().GetMemoryBytes()).To(Equal(uint64(1024)))
+	})
+
+	It("matches arbitrary predicates", func() {
+		Expect(metricEnvelope).To(EnvelopeWhere("a positive value", func(envelope *events.Envelope) bool {
+			return envelope.GetValueMetric().GetValue() > 0
+		}))
+	})
+
+	It("requires every chained condition to hold", func() {
+		Expect(httpEnvelope).To(EnvelopeOfType(events.Envelope_HttpStartStop).ForApp(appGuid).WithStatusCode(200))
+		Expect(httpEnvelope).NotTo(EnvelopeOfType(events.Envelope_HttpStartStop).ForApp(appGuid).WithStatusCode(404))
+	})
+
+	It("works with ContainElement", func() {
+		Expect([]*events.Envelope{logEnvelope, metricEnvelope}).To(ContainElement(EnvelopeWithValueMetric("memory")))
+	})
+
+	It("reports the conditions that did not hold", func() {
+		matcher := EnvelopeOfType(events.Envelope_HttpStartStop).FromOrigin("gorouter").WithStatusCode(404)
+
+		success, err := matcher.Match(httpEnvelope)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(success).To(BeFalse())
+		Expect(matcher.FailureMessage(httpEnvelope)).To(ContainSubstring("but it did not have HTTP status code 404"))
+	})
+
+	It("errors when the actual value is not an envelope", func() {
+		_, err := EnvelopeFromOrigin("gorouter").Match("not an envelope")
+		Expect(err).To(HaveOccurred())
+	})
+})
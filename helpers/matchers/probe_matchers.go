@@ -0,0 +1,87 @@
+package matchers
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/appclient"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+)
+
+// BeReachableWithBody succeeds when the app itself answered a probe with a
+// 2xx status and a body matching expected, which is a matcher or a value the
+// body must equal.
+func BeReachableWithBody(expected interface{}) types.GomegaMatcher {
+	bodyMatcher, ok := expected.(types.GomegaMatcher)
+	if !ok {
+		bodyMatcher = gomega.Equal(expected)
+	}
+	return &probeMatcher{
+		description: "to be reachable",
+		bodyMatcher: bodyMatcher,
+		holds: func(result appclient.ProbeResult) bool {
+			return result.Err == nil && result.RouterError() == "" && result.StatusCode/100 == 2
+		},
+	}
+}
+
+// BeUnroutable succeeds when the router answered a probe with a 404 because it
+// has no route to the app. A 404 from the app itself does not match.
+func BeUnroutable() types.GomegaMatcher {
+	return &probeMatcher{
+		description: "to be unroutable",
+		holds: func(result appclient.ProbeResult) bool {
+			return result.Unroutable()
+		},
+	}
+}
+
+// ReturnStatus succeeds when a probe got a response with the status code,
+// from the app or the router.
+func ReturnStatus(statusCode int) types.GomegaMatcher {
+	return &probeMatcher{
+		description: fmt.Sprintf("to return status %d", statusCode),
+		holds: func(result appclient.ProbeResult) bool {
+			return result.Err == nil && result.StatusCode == statusCode
+		},
+	}
+}
+
+type probeMatcher struct {
+	description string
+	holds       func(appclient.ProbeResult) bool
+	bodyMatcher types.GomegaMatcher
+
+	bodyMismatch bool
+}
+
+func (matcher *probeMatcher) Match(actual interface{}) (success bool, err error) {
+	result, ok := actual.(appclient.ProbeResult)
+	if !ok {
+		return false, fmt.Errorf("probe matchers expect an appclient.ProbeResult, got %T", actual)
+	}
+
+	matcher.bodyMismatch = false
+	if !matcher.holds(result) {
+		return false, nil
+	}
+	if matcher.bodyMatcher == nil {
+		return true, nil
+	}
+
+	success, err = matcher.bodyMatcher.Match(result.Body)
+	matcher.bodyMismatch = !success
+	return success, err
+}
+
+func (matcher *probeMatcher) FailureMessage(actual interface{}) (message string) {
+	if matcher.bodyMismatch {
+		result := actual.(appclient.ProbeResult)
+		return fmt.Sprintf("Expected the body from %s to match, but:\n%s", result.URL, matcher.bodyMatcher.FailureMessage(result.Body))
+	}
+	return fmt.Sprintf("Expected the app %s, but got %s", matcher.description, actual)
+}
+
+func (matcher *probeMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("Expected the app not %s, but got %s", matcher.description, actual)
+}
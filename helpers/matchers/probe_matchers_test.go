@@ -0,0 +1,75 @@
+package matchers_test
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/appclient"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/matchers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Probe matchers", func() {
+	probe := func(statusCode int, header http.Header, body string) appclient.ProbeResult {
+		return appclient.ProbeResult{
+			Response: appclient.Response{StatusCode: statusCode, Header: header, Body: body},
+			URL:      "http://dora.example.com/",
+		}
+	}
+
+	dora := probe(200, http.Header{}, "Hi, I'm Dora!")
+	appNotFound := probe(404, http.Header{}, "Not found")
+	routerNotFound := probe(404, http.Header{"X-Cf-Routererror": {"unknown_route"}}, "404 Not Found: Requested route ('dora.example.com') does not exist.")
+	badGateway := probe(502, http.Header{"X-Cf-Routererror": {"endpoint_failure"}}, "502 Bad Gateway")
+	noResponse := appclient.ProbeResult{URL: "http://dora.example.com/", Err: errors.New("connection refused")}
+
+	Describe("BeReachableWithBody", func() {
+		It("matches a successful response from the app by body", func() {
+			Expect(dora).To(BeReachableWithBody(ContainSubstring("Dora")))
+			Expect(dora).To(BeReachableWithBody("Hi, I'm Dora!"))
+			Expect(dora).NotTo(BeReachableWithBody(ContainSubstring("Dolly")))
+		})
+
+		It("does not match errors or responses from the router", func() {
+			Expect(appNotFound).NotTo(BeReachableWithBody(ContainSubstring("Not found")))
+			Expect(routerNotFound).NotTo(BeReachableWithBody(ContainSubstring("404")))
+			Expect(badGateway).NotTo(BeReachableWithBody(ContainSubstring("502")))
+			Expect(noResponse).NotTo(BeReachableWithBody(BeEmpty()))
+		})
+
+		It("explains a body mismatch with the body matcher's message", func() {
+			matcher := BeReachableWithBody(ContainSubstring("Dolly"))
+			matcher.Match(dora)
+			Expect(matcher.FailureMessage(dora)).To(ContainSubstring("Expected the body from http://dora.example.com/ to match"))
+			Expect(matcher.FailureMessage(dora)).To(ContainSubstring("to contain substring"))
+		})
+	})
+
+	Describe("BeUnroutable", func() {
+		It("only matches the router's 404", func() {
+			Expect(routerNotFound).To(BeUnroutable())
+			Expect(appNotFound).NotTo(BeUnroutable())
+			Expect(noResponse).NotTo(BeUnroutable())
+		})
+
+		It("describes what came back instead", func() {
+			Expect(BeUnroutable().FailureMessage(dora)).To(Equal("Expected the app to be unroutable, but got status 200 from http://dora.example.com/:\nHi, I'm Dora!"))
+		})
+	})
+
+	Describe("ReturnStatus", func() {
+		It("matches the status code of any response", func() {
+			Expect(badGateway).To(ReturnStatus(502))
+			Expect(appNotFound).To(ReturnStatus(404))
+			Expect(dora).NotTo(ReturnStatus(502))
+			Expect(noResponse).NotTo(ReturnStatus(0))
+		})
+	})
+
+	It("rejects values that are not probe results", func() {
+		_, err := BeUnroutable().Match("404")
+		Expect(err).To(HaveOccurred())
+	})
+})
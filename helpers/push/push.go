@@ -0,0 +1,185 @@
+// Package push pushes test assets with a manifest rendered from the asset's
+// manifest template, so that specs state how an app is pushed as a typed
+// Options value instead of a list of cf flags.
+package push
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"text/template"
+
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+)
+
+// ManifestTemplate is the file an asset directory may ship to describe how it
+// is pushed by default. It is rendered with text/template; {{.Name}} is the
+// app name, and {{env "KEY" "value"}} declares a default environment
+// variable. Templates describe a single application and must not have an env
+// section of their own.
+const ManifestTemplate = "manifest.yml.tmpl"
+
+// Options override what the asset's manifest template says. Zero values
+// leave the template, or the platform default, in charge.
+type Options struct {
+	Buildpack       string
+	Stack           string
+	Command         string
+	Memory          string
+	DiskQuota       string
+	Instances       int
+	HealthCheckType string
+	Hostname        string
+	Domain          string
+
+	// StartTimeout is the maximum number of seconds to wait for the app to
+	// start.
+	StartTimeout int
+
+	// Env is merged over the variables the template declares.
+	Env map[string]string
+
+	NoStart bool
+	NoRoute bool
+}
+
+// Push renders the asset's manifest for appName with the options and starts
+// `cf push` with it. Wait on the returned session as for cf.Cf.
+func Push(appName, assetPath string, options Options) *gexec.Session {
+	manifest, err := WriteManifest(appName, assetPath, options)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	args := append([]string{"push", appName, "-p", assetPath, "-f", manifest}, options.Args()...)
+	cleanup.Current().App(appName)
+	return cf.Cf(args...)
+}
+
+// PushWithRandomName pushes the asset as a new app with a random name and
+// returns the name with the push session.
+func PushWithRandomName(assetPath string, options Options) (string, *gexec.Session) {
+	appName := generator.RandomName()
+	return appName, Push(appName, assetPath, options)
+}
+
+// WriteManifest renders the manifest for pushing the asset as appName to a
+// temporary file and returns its path. The file is recorded in the cleanup
+// tracker, since cf push reads it after Push returns.
+func WriteManifest(appName, assetPath string, options Options) (string, error) {
+	manifest, err := RenderManifest(appName, assetPath, options)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := ioutil.TempFile("", "cats-manifest-")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	cleanup.Current().Track("manifest "+file.Name(), func() error {
+		return os.Remove(file.Name())
+	})
+
+	_, err = file.Write(manifest)
+	return file.Name(), err
+}
+
+// RenderManifest renders the asset's manifest template, or a minimal
+// manifest if it has none, and adds the env section.
+func RenderManifest(appName, assetPath string, options Options) ([]byte, error) {
+	env := map[string]string{}
+	functions := template.FuncMap{
+		"env": func(name, value string) string {
+			env[name] = value
+			return ""
+		},
+	}
+
+	source := "---\napplications:\n- name: {{.Name}}\n"
+	templatePath := filepath.Join(assetPath, ManifestTemplate)
+	contents, err := ioutil.ReadFile(templatePath)
+	if err == nil {
+		source = string(contents)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	manifestTemplate, err := template.New(templatePath).Funcs(functions).Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest bytes.Buffer
+	err = manifestTemplate.Execute(&manifest, struct{ Name string }{appName})
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range options.Env {
+		env[name] = value
+	}
+	if len(env) == 0 {
+		return manifest.Bytes(), nil
+	}
+
+	names := []string{}
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rendered := bytes.TrimRight(manifest.Bytes(), "\n")
+	manifest.Reset()
+	manifest.Write(rendered)
+	manifest.WriteString("\n  env:\n")
+	for _, name := range names {
+		// JSON strings are valid double-quoted YAML scalars.
+		quotedName, _ := json.Marshal(name)
+		quotedValue, _ := json.Marshal(env[name])
+		fmt.Fprintf(&manifest, "    %s: %s\n", quotedName, quotedValue)
+	}
+	return manifest.Bytes(), nil
+}
+
+// Args returns the cf push flags for the options, which take precedence over
+// the manifest.
+func (o Options) Args() []string {
+	args := []string{}
+	add := func(flag, value string) {
+		if value != "" {
+			args = append(args, flag, value)
+		}
+	}
+
+	add("-b", o.Buildpack)
+	add("-s", o.Stack)
+	add("-c", o.Command)
+	add("-m", o.Memory)
+	add("-k", o.DiskQuota)
+	if o.Instances > 0 {
+		add("-i", strconv.Itoa(o.Instances))
+	}
+	add("-u", o.HealthCheckType)
+	add("-n", o.Hostname)
+	add("-d", o.Domain)
+	if o.StartTimeout > 0 {
+		add("-t", strconv.Itoa(o.StartTimeout))
+	}
+	if o.NoStart {
+		args = append(args, "--no-start")
+	}
+	if o.NoRoute {
+		args = append(args, "--no-route")
+	}
+	return args
+}
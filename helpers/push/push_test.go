@@ -0,0 +1,137 @@
+package push_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/push"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Push", func() {
+	var assetPath string
+
+	writeTemplate := func(contents string) {
+		Expect(ioutil.WriteFile(filepath.Join(assetPath, push.ManifestTemplate), []byte(contents), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		assetPath, err = ioutil.TempDir("", "push-asset")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(assetPath)
+	})
+
+	Describe("RenderManifest", func() {
+		It("renders the asset's template with the app name", func() {
+			writeTemplate("---\napplications:\n- name: {{.Name}}\n  command: node app.js\n")
+
+			manifest, err := push.RenderManifest("my-app", assetPath, push.Options{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(manifest)).To(Equal("---\napplications:\n- name: my-app\n  command: node app.js\n"))
+		})
+
+		It("renders a minimal manifest for assets without a template", func() {
+			manifest, err := push.RenderManifest("my-app", assetPath, push.Options{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(manifest)).To(Equal("---\napplications:\n- name: my-app\n"))
+		})
+
+		It("merges the env given in the options over the template's", func() {
+			writeTemplate("---\napplications:\n- name: {{.Name}}\n  memory: 512M\n{{env \"JAVA_OPTS\" \"-Xss1m\"}}{{env \"MODE\" \"default\"}}")
+
+			manifest, err := push.RenderManifest("my-app", assetPath, push.Options{
+				Env: map[string]string{"MODE": "test: \"quoted\""},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(manifest)).To(Equal(`---
+applications:
+- name: my-app
+  memory: 512M
+  env:
+    "JAVA_OPTS": "-Xss1m"
+    "MODE": "test: \"quoted\""
+`))
+		})
+
+		It("reports template errors", func() {
+			writeTemplate("- name: {{.Nmae}}\n")
+
+			_, err := push.RenderManifest("my-app", assetPath, push.Options{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Args", func() {
+		It("only includes the options that are set", func() {
+			Expect(push.Options{}.Args()).To(BeEmpty())
+		})
+
+		It("translates the options to cf push flags", func() {
+			options := push.Options{
+				Buildpack:       "ruby_buildpack",
+				Stack:           "cflinuxfs2",
+				Command:         "bundle exec rackup",
+				Memory:          "256M",
+				DiskQuota:       "1G",
+				Instances:       2,
+				HealthCheckType: "none",
+				Hostname:        "my-host",
+				Domain:          "example.com",
+				StartTimeout:    180,
+				NoStart:         true,
+				NoRoute:         true,
+			}
+
+			Expect(options.Args()).To(Equal([]string{
+				"-b", "ruby_buildpack",
+				"-s", "cflinuxfs2",
+				"-c", "bundle exec rackup",
+				"-m", "256M",
+				"-k", "1G",
+				"-i", "2",
+				"-u", "none",
+				"-n", "my-host",
+				"-d", "example.com",
+				"-t", "180",
+				"--no-start",
+				"--no-route",
+			}))
+		})
+	})
+
+	Describe("Push", func() {
+		It("pushes the asset with the rendered manifest and the option flags", func() {
+			writeTemplate("---\napplications:\n- name: {{.Name}}\n")
+
+			Expect(push.Push("my-app", assetPath, push.Options{Memory: "128M"}).Wait()).To(Exit(0))
+
+			invocations := fake.CF.InvocationsOf("cf push")
+			Expect(invocations).To(HaveLen(1))
+
+			args := invocations[0].Args
+			Expect(args[:4]).To(Equal([]string{"push", "my-app", "-p", assetPath}))
+			Expect(args[4]).To(Equal("-f"))
+			Expect(args[6:]).To(Equal([]string{"-m", "128M"}))
+
+			manifest, err := ioutil.ReadFile(args[5])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(manifest)).To(ContainSubstring("- name: my-app"))
+		})
+
+		It("can pick a random app name", func() {
+			appName, session := push.PushWithRandomName(assetPath, push.Options{})
+			Expect(session.Wait()).To(Exit(0))
+
+			Expect(appName).NotTo(BeEmpty())
+			Expect(fake.CF.InvocationsOf("cf push")[0].Args[1]).To(Equal(appName))
+		})
+	})
+})
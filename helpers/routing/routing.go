@@ -0,0 +1,27 @@
+// Package routing moves routes between apps with cf, e.g. to switch traffic
+// from one version of an app to the next.
+package routing
+
+import (
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+)
+
+// MapRoute sends the traffic of host.domain to the app as well, creating the
+// route if it doesn't exist yet. The route is recorded for deletion after the
+// spec, as it outlives the apps it is mapped to.
+func MapRoute(appName, domain, host string, timeout time.Duration) {
+	cleanup.Current().Route(domain, host)
+	ExpectWithOffset(1, cf.Cf("map-route", appName, domain, "-n", host).Wait(timeout)).To(Exit(0))
+}
+
+// UnmapRoute stops sending the traffic of host.domain to the app. The route
+// itself stays.
+func UnmapRoute(appName, domain, host string, timeout time.Duration) {
+	ExpectWithOffset(1, cf.Cf("unmap-route", appName, domain, "-n", host).Wait(timeout)).To(Exit(0))
+}
@@ -0,0 +1,20 @@
+package routing_test
+
+import (
+	"testing"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	tracker = fakes.InstallTracker()
+	fake    = fakes.InstallForSuite()
+)
+
+func TestRouting(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Routing Suite")
+}
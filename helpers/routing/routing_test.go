@@ -0,0 +1,29 @@
+package routing_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/routing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routing", func() {
+	It("maps the route to the app and records it for deletion", func() {
+		routing.MapRoute("green", "example.com", "my-host", time.Second)
+
+		Expect(fake.CF.InvocationsOf("cf map-route")[0].String()).To(Equal("cf map-route green example.com -n my-host"))
+		Expect(tracker.Pending()).To(Equal([]string{"route my-host.example.com"}))
+
+		Expect(tracker.Cleanup()).To(Succeed())
+		Expect(fake.CF.InvocationsOf("cf delete-route")[0].String()).To(Equal("cf delete-route example.com -n my-host -f"))
+	})
+
+	It("unmaps the route from the app and leaves it in place", func() {
+		routing.UnmapRoute("blue", "example.com", "my-host", time.Second)
+
+		Expect(fake.CF.InvocationsOf("cf unmap-route")[0].String()).To(Equal("cf unmap-route blue example.com -n my-host"))
+		Expect(tracker.Pending()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,102 @@
+// Package suite provides the SuiteContext CATS suites run with. It wraps the
+// cf-test-helpers ConfiguredContext, which gives every ginkgo node one org and
+// one space shared by all of the node's specs and a regular user holding every
+// space role. Specs that interfere with their siblings can opt into a space of
+// their own, and specs about authorization can get users with a single role.
+package suite
+
+import (
+	"sync"
+
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+)
+
+type SuiteContext interface {
+	helpers.SuiteContext
+
+	// IsolateSpace gives the current spec a new space in the node's org.
+	IsolateSpace()
+
+	// UserWithRole returns a user that holds nothing but the role.
+	UserWithRole(role Role) cf.UserContext
+
+	// ApplySpaceQuota limits the targeted space for the current spec.
+	ApplySpaceQuota(quota Quota)
+}
+
+type ConfiguredContext struct {
+	*helpers.ConfiguredContext
+
+	lock          sync.Mutex
+	isolatedSpace string
+	roleUsers     map[Role]*roleUser
+}
+
+func NewContext(config helpers.Config) *ConfiguredContext {
+	return &ConfiguredContext{ConfiguredContext: helpers.NewContext(config)}
+}
+
+// RegularUserContext targets the isolated space while a spec has one, and the
+// node's space otherwise.
+func (context *ConfiguredContext) RegularUserContext() cf.UserContext {
+	userContext := context.ConfiguredContext.RegularUserContext()
+
+	context.lock.Lock()
+	defer context.lock.Unlock()
+	if context.isolatedSpace != "" {
+		userContext.Space = context.isolatedSpace
+	}
+	return userContext
+}
+
+// IsolateSpace creates a space in the node's org, makes the regular user a
+// manager, developer and auditor of it, as of the node's space, and targets it
+// for the rest of the spec. Call it from a BeforeEach, before creating
+// anything else.
+//
+// The space is recorded with the cleanup tracker, so it is deleted after the
+// spec once everything recorded after it has been removed.
+func (context *ConfiguredContext) IsolateSpace() {
+	space := "CATS-SPACE-" + generator.RandomName()
+	regularUser := context.ConfiguredContext.RegularUserContext()
+
+	cf.AsUser(context.AdminUserContext(), func() {
+		Expect(cf.Cf("create-space", "-o", regularUser.Org, space).Wait(helpers.CF_API_TIMEOUT)).To(Exit(0))
+		cleanup.Current().Track("isolated space "+space, func() error {
+			context.leaveIsolatedSpace(space)
+			return nil
+		})
+
+		for _, role := range []string{"SpaceManager", "SpaceDeveloper", "SpaceAuditor"} {
+			Expect(cf.Cf("set-space-role", regularUser.Username, regularUser.Org, space, role).Wait(helpers.CF_API_TIMEOUT)).To(Exit(0))
+		}
+	})
+
+	context.lock.Lock()
+	context.isolatedSpace = space
+	context.lock.Unlock()
+
+	cf.TargetSpace(context.RegularUserContext())
+}
+
+// leaveIsolatedSpace targets the node's space again and deletes the isolated
+// one as the admin.
+func (context *ConfiguredContext) leaveIsolatedSpace(space string) {
+	context.lock.Lock()
+	context.isolatedSpace = ""
+	context.lock.Unlock()
+
+	cf.TargetSpace(context.RegularUserContext())
+
+	admin := context.AdminUserContext()
+	admin.Org = context.RegularUserContext().Org
+	cf.AsUser(admin, func() {
+		Expect(cf.Cf("delete-space", space, "-f").Wait(helpers.CF_API_TIMEOUT)).To(Exit(0))
+	})
+}
@@ -0,0 +1,95 @@
+package suite_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakes"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/suite"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConfiguredContext", func() {
+	context := suite.NewContext(helpers.Config{
+		ApiEndpoint:   "api.example.com",
+		AdminUser:     "admin",
+		AdminPassword: "secret",
+	})
+	org := context.RegularUserContext().Org
+	nodeSpace := context.RegularUserContext().Space
+	username := context.RegularUserContext().Username
+
+	commands := func() []string {
+		lines := []string{}
+		for _, invocation := range fake.CF.Invocations() {
+			lines = append(lines, invocation.String())
+		}
+		return lines
+	}
+
+	It("is a SuiteContext", func() {
+		var _ suite.SuiteContext = context
+	})
+
+	It("uses the node's space outside of isolated containers", func() {
+		Expect(nodeSpace).To(HavePrefix("CATS-SPACE-"))
+		Expect(fake.CF.Invocations()).To(BeEmpty())
+	})
+
+	Describe("IsolateSpace", func() {
+		It("gives the spec a space of its own and targets it", func() {
+			context.IsolateSpace()
+
+			isolatedSpace := context.RegularUserContext().Space
+			Expect(isolatedSpace).To(HavePrefix("CATS-SPACE-"))
+			Expect(isolatedSpace).NotTo(Equal(nodeSpace))
+			Expect(context.RegularUserContext().Org).To(Equal(org))
+
+			Expect(commands()).To(Equal([]string{
+				"cf api api.example.com",
+				"cf auth admin secret",
+				"cf create-space -o " + org + " " + isolatedSpace,
+				strings.Join([]string{"cf set-space-role", username, org, isolatedSpace, "SpaceManager"}, " "),
+				strings.Join([]string{"cf set-space-role", username, org, isolatedSpace, "SpaceDeveloper"}, " "),
+				strings.Join([]string{"cf set-space-role", username, org, isolatedSpace, "SpaceAuditor"}, " "),
+				"cf logout",
+				fmt.Sprintf("cf target -o %s -s %s", org, isolatedSpace),
+			}))
+		})
+
+		It("deletes the space after what was created in it, and targets the node's space again", func() {
+			context.IsolateSpace()
+			isolatedSpace := context.RegularUserContext().Space
+			tracker.App("my-app")
+
+			Expect(tracker.Cleanup()).To(Succeed())
+			Expect(context.RegularUserContext().Space).To(Equal(nodeSpace))
+
+			lines := commands()
+			Expect(lines[len(lines)-7:]).To(Equal([]string{
+				"cf delete my-app -f -r",
+				fmt.Sprintf("cf target -o %s -s %s", org, nodeSpace),
+				"cf api api.example.com",
+				"cf auth admin secret",
+				"cf target -o " + org,
+				"cf delete-space " + isolatedSpace + " -f",
+				"cf logout",
+			}))
+		})
+
+		It("still deletes the space when granting roles fails", func() {
+			fake.CF.Handle("cf set-space-role", fakes.Failure(1, "FAILED"))
+
+			failures := InterceptGomegaFailures(func() {
+				context.IsolateSpace()
+			})
+			Expect(failures).NotTo(BeEmpty())
+
+			Expect(tracker.Cleanup()).To(Succeed())
+			Expect(fake.CF.InvocationsOf("cf delete-space")).To(HaveLen(1))
+		})
+	})
+})
@@ -0,0 +1,116 @@
+package suite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+)
+
+// Unlimited lifts a limit of a quota.
+const Unlimited = -1
+
+// Quota holds the limits of an org or space quota. Start from DefaultQuota
+// and lower the limits a spec is about, so that the others stay out of the
+// way.
+type Quota struct {
+	Name string
+
+	// Memory is the total memory of all app instances, e.g. "64M".
+	Memory string
+
+	// InstanceMemory is the memory of a single app instance, or Unlimited.
+	InstanceMemory int
+
+	Routes           int
+	ServiceInstances int
+
+	// NonBasicServicesAllowed allows instances of paid service plans.
+	NonBasicServicesAllowed bool
+}
+
+// DefaultQuota returns the limits of the quota every node's org runs with.
+func DefaultQuota() Quota {
+	return Quota{
+		Name:                    "CATS-QUOTA-" + generator.RandomName(),
+		Memory:                  "10G",
+		InstanceMemory:          Unlimited,
+		Routes:                  1000,
+		ServiceInstances:        100,
+		NonBasicServicesAllowed: true,
+	}
+}
+
+// Args returns the flags create-quota and create-space-quota take for the
+// limits.
+func (quota Quota) Args() []string {
+	instanceMemory := strconv.Itoa(quota.InstanceMemory)
+	if quota.InstanceMemory != Unlimited {
+		instanceMemory += "M"
+	}
+
+	args := []string{
+		"-m", quota.Memory,
+		"-i", instanceMemory,
+		"-r", strconv.Itoa(quota.Routes),
+		"-s", strconv.Itoa(quota.ServiceInstances),
+	}
+	if quota.NonBasicServicesAllowed {
+		args = append(args, "--allow-paid-service-plans")
+	}
+	return args
+}
+
+// CreateOrgQuota creates the org quota as the current user, who must be an
+// admin, and records it for deletion after the spec.
+func CreateOrgQuota(quota Quota) {
+	args := append([]string{"create-quota", quota.Name}, quota.Args()...)
+	ExpectWithOffset(1, cf.Cf(args...).Wait(helpers.CF_API_TIMEOUT)).To(Exit(0))
+	cleanup.Current().Quota(quota.Name)
+}
+
+// ApplySpaceQuota creates a space quota in the node's org and assigns it to
+// the targeted space for the rest of the spec. Call IsolateSpace first, or the
+// limits apply to every spec on the node.
+func (context *ConfiguredContext) ApplySpaceQuota(quota Quota) {
+	regularUser := context.RegularUserContext()
+	admin := context.AdminUserContext()
+	admin.Org = regularUser.Org
+
+	cf.AsUser(admin, func() {
+		args := append([]string{"create-space-quota", quota.Name}, quota.Args()...)
+		Expect(cf.Cf(args...).Wait(helpers.CF_API_TIMEOUT)).To(Exit(0))
+	})
+	cleanup.Current().Track("space quota "+quota.Name, context.asAdminInOrg(regularUser.Org, "delete-space-quota", quota.Name, "-f"))
+
+	cf.AsUser(admin, func() {
+		Expect(cf.Cf("set-space-quota", regularUser.Space, quota.Name).Wait(helpers.CF_API_TIMEOUT)).To(Exit(0))
+	})
+	cleanup.Current().Track("space quota "+quota.Name+" assignment to "+regularUser.Space,
+		context.asAdminInOrg(regularUser.Org, "unset-space-quota", regularUser.Space, quota.Name))
+}
+
+// asAdminInOrg returns a cleanup function that runs the cf command as the
+// admin, targeting the org, and fails if the command does.
+func (context *ConfiguredContext) asAdminInOrg(org string, args ...string) func() error {
+	admin := context.AdminUserContext()
+	admin.Org = org
+
+	return func() error {
+		var err error
+		cf.AsUser(admin, func() {
+			session := cf.Cf(args...).Wait(helpers.CF_API_TIMEOUT)
+			if session.ExitCode() != 0 {
+				err = fmt.Errorf("cf %s exited with %d:\n%s", strings.Join(args, " "), session.ExitCode(), session.Out.Contents())
+			}
+		})
+		return err
+	}
+}
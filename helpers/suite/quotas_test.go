@@ -0,0 +1,86 @@
+package suite_test
+
+import (
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakes"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/suite"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Quotas", func() {
+	context := suite.NewContext(helpers.Config{
+		ApiEndpoint:   "api.example.com",
+		AdminUser:     "admin",
+		AdminPassword: "secret",
+	})
+
+	Describe("Quota", func() {
+		It("defaults to the limits of the node's org quota", func() {
+			quota := suite.DefaultQuota()
+			Expect(quota.Name).To(HavePrefix("CATS-QUOTA-"))
+			Expect(quota.Args()).To(Equal([]string{"-m", "10G", "-i", "-1", "-r", "1000", "-s", "100", "--allow-paid-service-plans"}))
+		})
+
+		It("turns the limits into cf flags", func() {
+			quota := suite.DefaultQuota()
+			quota.Memory = "64M"
+			quota.InstanceMemory = 32
+			quota.Routes = 0
+			quota.ServiceInstances = suite.Unlimited
+			quota.NonBasicServicesAllowed = false
+			Expect(quota.Args()).To(Equal([]string{"-m", "64M", "-i", "32M", "-r", "0", "-s", "-1"}))
+		})
+	})
+
+	Describe("CreateOrgQuota", func() {
+		It("creates the quota and deletes it after the spec", func() {
+			quota := suite.DefaultQuota()
+			suite.CreateOrgQuota(quota)
+			Expect(fake.CF.InvocationsOf("cf create-quota")[0].Args).To(Equal(append([]string{"create-quota", quota.Name}, quota.Args()...)))
+
+			Expect(tracker.Cleanup()).To(Succeed())
+			Expect(fake.CF.InvocationsOf("cf delete-quota")[0].String()).To(Equal("cf delete-quota " + quota.Name + " -f"))
+		})
+	})
+
+	Describe("ApplySpaceQuota", func() {
+		It("assigns a new space quota to the targeted space until the spec ends", func() {
+			org := context.RegularUserContext().Org
+			space := context.RegularUserContext().Space
+			quota := suite.DefaultQuota()
+			quota.Memory = "64M"
+
+			context.ApplySpaceQuota(quota)
+			Expect(fake.CF.InvocationsOf("cf create-space-quota")[0].Args).To(Equal(append([]string{"create-space-quota", quota.Name}, quota.Args()...)))
+			Expect(fake.CF.InvocationsOf("cf set-space-quota")[0].String()).To(Equal("cf set-space-quota " + space + " " + quota.Name))
+			Expect(fake.CF.InvocationsOf("cf target")[0].String()).To(Equal("cf target -o " + org))
+
+			Expect(tracker.Cleanup()).To(Succeed())
+			Expect(tracker.Pending()).To(BeEmpty())
+
+			removals := []string{}
+			for _, invocation := range fake.CF.Invocations() {
+				if invocation.Args[0] == "unset-space-quota" || invocation.Args[0] == "delete-space-quota" {
+					removals = append(removals, invocation.String())
+				}
+			}
+			Expect(removals).To(Equal([]string{
+				"cf unset-space-quota " + space + " " + quota.Name,
+				"cf delete-space-quota " + quota.Name + " -f",
+			}))
+		})
+
+		It("reports a space quota it cannot remove", func() {
+			quota := suite.DefaultQuota()
+			context.ApplySpaceQuota(quota)
+			fake.CF.Handle("cf delete-space-quota", fakes.Failure(1, "FAILED\nQuota is in use"))
+
+			err := tracker.Cleanup()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("space quota " + quota.Name + ": cf delete-space-quota " + quota.Name + " -f exited with 1"))
+			Expect(err.Error()).To(ContainSubstring("Quota is in use"))
+		})
+	})
+})
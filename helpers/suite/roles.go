@@ -0,0 +1,108 @@
+package suite
+
+import (
+	"strings"
+
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+)
+
+// Role is an org or space role, named as cf set-org-role and set-space-role
+// expect it.
+type Role string
+
+const (
+	OrgManager     Role = "OrgManager"
+	OrgAuditor     Role = "OrgAuditor"
+	BillingManager Role = "BillingManager"
+	SpaceDeveloper Role = "SpaceDeveloper"
+	SpaceAuditor   Role = "SpaceAuditor"
+)
+
+// IsSpaceRole tells space roles, which are granted in the node's space, from
+// org roles, which are granted in the node's org.
+func (role Role) IsSpaceRole() bool {
+	return strings.HasPrefix(string(role), "Space")
+}
+
+type roleUser struct {
+	userContext cf.UserContext
+	grantedIn   map[string]bool
+}
+
+// UserWithRole returns a user holding only the role. Each role has its own
+// user, created on first use and deleted in Teardown. Space roles are granted
+// in the space the regular user targets, isolated or not, and the returned
+// context targets that space; org roles only target the org.
+func (context *ConfiguredContext) UserWithRole(role Role) cf.UserContext {
+	regularUser := context.RegularUserContext()
+
+	context.lock.Lock()
+	defer context.lock.Unlock()
+
+	if context.roleUsers == nil {
+		context.roleUsers = map[Role]*roleUser{}
+	}
+
+	user, ok := context.roleUsers[role]
+	if !ok {
+		user = &roleUser{
+			userContext: cf.NewUserContext(
+				regularUser.ApiUrl,
+				regularUser.Username+"-"+strings.ToLower(string(role)),
+				generator.RandomName(),
+				regularUser.Org,
+				"",
+				regularUser.SkipSSLValidation,
+			),
+			grantedIn: map[string]bool{},
+		}
+		cf.AsUser(context.AdminUserContext(), func() {
+			createUser := cf.Cf("create-user", user.userContext.Username, user.userContext.Password).Wait(helpers.CF_API_TIMEOUT)
+			if createUser.ExitCode() != 0 {
+				Expect(createUser.Out).To(Say("scim_resource_already_exists"))
+			}
+		})
+		context.roleUsers[role] = user
+	}
+
+	userContext := user.userContext
+	if role.IsSpaceRole() {
+		userContext.Space = regularUser.Space
+	}
+
+	if !user.grantedIn[userContext.Space] {
+		cf.AsUser(context.AdminUserContext(), func() {
+			if role.IsSpaceRole() {
+				Expect(cf.Cf("set-space-role", userContext.Username, userContext.Org, userContext.Space, string(role)).Wait(helpers.CF_API_TIMEOUT)).To(Exit(0))
+			} else {
+				Expect(cf.Cf("set-org-role", userContext.Username, userContext.Org, string(role)).Wait(helpers.CF_API_TIMEOUT)).To(Exit(0))
+			}
+		})
+		user.grantedIn[userContext.Space] = true
+	}
+
+	return userContext
+}
+
+// Teardown deletes the users created by UserWithRole before the regular user,
+// org and quota.
+func (context *ConfiguredContext) Teardown() {
+	context.lock.Lock()
+	roleUsers := context.roleUsers
+	context.roleUsers = nil
+	context.lock.Unlock()
+
+	cf.AsUser(context.AdminUserContext(), func() {
+		for _, user := range roleUsers {
+			Expect(cf.Cf("delete-user", "-f", user.userContext.Username).Wait(helpers.CF_API_TIMEOUT)).To(Exit(0))
+		}
+	})
+
+	context.ConfiguredContext.Teardown()
+}
@@ -0,0 +1,88 @@
+package suite_test
+
+import (
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakes"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/suite"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UserWithRole", func() {
+	var (
+		context  *suite.ConfiguredContext
+		org      string
+		space    string
+		username string
+	)
+
+	BeforeEach(func() {
+		context = suite.NewContext(helpers.Config{
+			ApiEndpoint:   "api.example.com",
+			AdminUser:     "admin",
+			AdminPassword: "secret",
+		})
+		org = context.RegularUserContext().Org
+		space = context.RegularUserContext().Space
+		username = context.RegularUserContext().Username
+	})
+
+	It("creates a user per role and grants space roles in the targeted space", func() {
+		developer := context.UserWithRole(suite.SpaceDeveloper)
+		Expect(developer.Username).To(Equal(username + "-spacedeveloper"))
+		Expect(developer.Org).To(Equal(org))
+		Expect(developer.Space).To(Equal(space))
+		Expect(developer.Password).NotTo(BeEmpty())
+
+		creates := fake.CF.InvocationsOf("cf create-user")
+		Expect(creates).To(HaveLen(1))
+		Expect(creates[0].Args).To(Equal([]string{"create-user", developer.Username, developer.Password}))
+
+		grants := fake.CF.InvocationsOf("cf set-space-role")
+		Expect(grants).To(HaveLen(1))
+		Expect(grants[0].String()).To(Equal("cf set-space-role " + developer.Username + " " + org + " " + space + " SpaceDeveloper"))
+	})
+
+	It("grants org roles in the org and only targets the org", func() {
+		manager := context.UserWithRole(suite.OrgManager)
+		Expect(manager.Username).To(Equal(username + "-orgmanager"))
+		Expect(manager.Space).To(BeEmpty())
+
+		grants := fake.CF.InvocationsOf("cf set-org-role")
+		Expect(grants).To(HaveLen(1))
+		Expect(grants[0].String()).To(Equal("cf set-org-role " + manager.Username + " " + org + " OrgManager"))
+		Expect(fake.CF.InvocationsOf("cf set-space-role")).To(BeEmpty())
+	})
+
+	It("creates and grants each role once", func() {
+		first := context.UserWithRole(suite.BillingManager)
+		second := context.UserWithRole(suite.BillingManager)
+		Expect(second).To(Equal(first))
+
+		Expect(fake.CF.InvocationsOf("cf create-user")).To(HaveLen(1))
+		Expect(fake.CF.InvocationsOf("cf set-org-role")).To(HaveLen(1))
+	})
+
+	It("reuses users left behind by an earlier run", func() {
+		fake.CF.Handle("cf create-user", fakes.Failure(1, "FAILED\nscim_resource_already_exists"))
+
+		context.UserWithRole(suite.OrgAuditor)
+		Expect(fake.CF.InvocationsOf("cf set-org-role")).To(HaveLen(1))
+	})
+
+	It("deletes the users it created on teardown, before the regular user", func() {
+		auditor := context.UserWithRole(suite.SpaceAuditor)
+		manager := context.UserWithRole(suite.OrgManager)
+
+		context.Teardown()
+
+		deletes := fake.CF.InvocationsOf("cf delete-user")
+		Expect(deletes).To(HaveLen(3))
+		Expect([]string{deletes[0].String(), deletes[1].String()}).To(ConsistOf(
+			"cf delete-user -f "+auditor.Username,
+			"cf delete-user -f "+manager.Username,
+		))
+		Expect(deletes[2].String()).To(Equal("cf delete-user -f " + username))
+	})
+})
@@ -0,0 +1,23 @@
+package suite_test
+
+import (
+	"testing"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	// tracker is what IsolateSpace and ApplySpaceQuota record resources in.
+	// Specs call Cleanup themselves to check what it runs.
+	tracker = fakes.InstallTracker()
+
+	fake = fakes.InstallForSuite()
+)
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Suite Context Suite")
+}
@@ -0,0 +1,64 @@
+package syslogdrain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const maxFrameLength = 1024 * 1024
+
+// Frame prepends the RFC 6587 octet count to a formatted message, the way
+// Loggregator writes messages to TCP and TLS drains.
+func Frame(message []byte) []byte {
+	return append([]byte(strconv.Itoa(len(message))+" "), message...)
+}
+
+// ReadFrame reads one message from a stream. Octet-counted frames ("LEN SP
+// MSG") are preferred; anything else is read as a newline-terminated message.
+func ReadFrame(reader *bufio.Reader) ([]byte, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if first[0] < '1' || first[0] > '9' {
+		return readLine(reader)
+	}
+
+	lengthField, err := reader.ReadString(' ')
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	length, err := strconv.Atoi(lengthField[:len(lengthField)-1])
+	if err != nil || length > maxFrameLength {
+		return nil, fmt.Errorf("invalid frame length '%s'", lengthField[:len(lengthField)-1])
+	}
+
+	frame := make([]byte, length)
+	_, err = io.ReadFull(reader, frame)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return frame, nil
+}
+
+func readLine(reader *bufio.Reader) ([]byte, error) {
+	line, err := reader.ReadBytes('\n')
+	if err == io.EOF && len(line) > 0 {
+		return line, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return line[:len(line)-1], nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package syslogdrain_test
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/syslogdrain"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadFrame", func() {
+	It("reads octet-counted frames captured from loggregator", func() {
+		captured, err := ioutil.ReadFile("testdata/loggregator.frames")
+		Expect(err).NotTo(HaveOccurred())
+
+		reader := bufio.NewReader(bytes.NewReader(captured))
+		sources := []string{}
+		for {
+			frame, err := syslogdrain.ReadFrame(reader)
+			if err == io.EOF {
+				break
+			}
+			Expect(err).NotTo(HaveOccurred())
+
+			message, err := syslogdrain.Parse(frame)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(message.AppGuid()).To(Equal("5f7b3c1e-7d4a-4b6e-9a51-0c9f3f1e2d7a"))
+			sources = append(sources, message.ProcessID)
+		}
+
+		Expect(sources).To(Equal([]string{"[App/0]", "[App/0]", "[RTR/1]", "[API/0]"}))
+	})
+
+	It("reads back what Frame writes", func() {
+		message := []byte("<14>1 - - - - - - a message with a\nnewline")
+		reader := bufio.NewReader(bytes.NewReader(syslogdrain.Frame(message)))
+
+		frame, err := syslogdrain.ReadFrame(reader)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(frame).To(Equal(message))
+	})
+
+	It("falls back to newline-terminated messages", func() {
+		reader := bufio.NewReader(strings.NewReader("<14>1 - - - - - - one\n<14>1 - - - - - - two"))
+
+		frame, err := syslogdrain.ReadFrame(reader)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(frame)).To(Equal("<14>1 - - - - - - one"))
+
+		frame, err = syslogdrain.ReadFrame(reader)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(frame)).To(Equal("<14>1 - - - - - - two"))
+
+		_, err = syslogdrain.ReadFrame(reader)
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("reports a truncated frame", func() {
+		reader := bufio.NewReader(strings.NewReader("20 <14>1 - - -"))
+
+		_, err := syslogdrain.ReadFrame(reader)
+		Expect(err).To(Equal(io.ErrUnexpectedEOF))
+	})
+
+	It("rejects an oversized length", func() {
+		reader := bufio.NewReader(strings.NewReader("99999999999 <14>1"))
+
+		_, err := syslogdrain.ReadFrame(reader)
+		Expect(err).To(MatchError("invalid frame length '99999999999'"))
+	})
+})
@@ -0,0 +1,202 @@
+package syslogdrain
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Listener accepts syslog messages over TCP, TLS or UDP and keeps them, in
+// the order they arrived, for inspection by specs.
+type Listener struct {
+	lock     sync.Mutex
+	messages []Message
+	errors   []error
+
+	streamListener net.Listener
+	packetConn     net.PacketConn
+	closing        chan struct{}
+	waitGroup      sync.WaitGroup
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// ListenTCP accepts octet-counted messages on a plain TCP socket, as sent to
+// syslog:// drains.
+func ListenTCP(address string) (*Listener, error) {
+	streamListener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	return newStreamListener(streamListener), nil
+}
+
+// ListenTLS accepts octet-counted messages over TLS, as sent to syslog-tls://
+// drains.
+func ListenTLS(address string, config *tls.Config) (*Listener, error) {
+	streamListener, err := tls.Listen("tcp", address, config)
+	if err != nil {
+		return nil, err
+	}
+	return newStreamListener(streamListener), nil
+}
+
+// ListenUDP accepts one message per datagram.
+func ListenUDP(address string) (*Listener, error) {
+	packetConn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Listener{packetConn: packetConn, closing: make(chan struct{})}
+	l.waitGroup.Add(1)
+	go l.readPackets()
+	return l, nil
+}
+
+func newStreamListener(streamListener net.Listener) *Listener {
+	l := &Listener{streamListener: streamListener, closing: make(chan struct{})}
+	l.waitGroup.Add(1)
+	go l.acceptConnections()
+	return l
+}
+
+func (l *Listener) Addr() net.Addr {
+	if l.packetConn != nil {
+		return l.packetConn.LocalAddr()
+	}
+	return l.streamListener.Addr()
+}
+
+// Close stops listening, closes open connections and waits for their
+// handlers to finish. Closing again returns the error of the first Close.
+func (l *Listener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.closing)
+
+		if l.packetConn != nil {
+			l.closeErr = l.packetConn.Close()
+		} else {
+			l.closeErr = l.streamListener.Close()
+		}
+		l.waitGroup.Wait()
+	})
+	return l.closeErr
+}
+
+// Messages returns every message received so far, in arrival order.
+func (l *Listener) Messages() []Message {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return append([]Message{}, l.messages...)
+}
+
+// MessagesFor returns the messages emitted by one app, in arrival order.
+func (l *Listener) MessagesFor(appGuid string) []Message {
+	matching := []Message{}
+	for _, message := range l.Messages() {
+		if message.AppGuid() == appGuid {
+			matching = append(matching, message)
+		}
+	}
+	return matching
+}
+
+// DidReceive reports whether any message body contains text.
+func (l *Listener) DidReceive(text string) bool {
+	for _, message := range l.Messages() {
+		if strings.Contains(message.Message, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors returns the framing and parse errors seen so far.
+func (l *Listener) Errors() []error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return append([]error{}, l.errors...)
+}
+
+func (l *Listener) acceptConnections() {
+	defer l.waitGroup.Done()
+
+	for {
+		conn, err := l.streamListener.Accept()
+		if err != nil {
+			return
+		}
+
+		l.waitGroup.Add(1)
+		go l.handleConnection(conn)
+	}
+}
+
+func (l *Listener) handleConnection(conn net.Conn) {
+	defer l.waitGroup.Done()
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-done:
+		case <-l.closing:
+			conn.Close()
+		}
+	}()
+
+	reader := bufio.NewReader(conn)
+	for {
+		frame, err := ReadFrame(reader)
+		if err != nil {
+			if err != io.EOF && !isClosedError(err) {
+				l.recordError(err)
+			}
+			return
+		}
+		l.record(frame)
+	}
+}
+
+func (l *Listener) readPackets() {
+	defer l.waitGroup.Done()
+
+	buffer := make([]byte, 65536)
+	for {
+		n, _, err := l.packetConn.ReadFrom(buffer)
+		if err != nil {
+			return
+		}
+		l.record(append([]byte{}, buffer[:n]...))
+	}
+}
+
+func isClosedError(err error) bool {
+	return strings.Contains(err.Error(), "use of closed network connection")
+}
+
+func (l *Listener) record(frame []byte) {
+	message, err := Parse(frame)
+	if err != nil {
+		l.recordError(err)
+		return
+	}
+
+	l.lock.Lock()
+	l.messages = append(l.messages, message)
+	l.lock.Unlock()
+}
+
+func (l *Listener) recordError(err error) {
+	l.lock.Lock()
+	l.errors = append(l.errors, err)
+	l.lock.Unlock()
+}
@@ -0,0 +1,150 @@
+package syslogdrain_test
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/syslogdrain"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func appMessage(appGuid string, instance int, text string) []byte {
+	return syslogdrain.Message{
+		Priority:  14,
+		Hostname:  "loggregator",
+		AppName:   appGuid,
+		ProcessID: fmt.Sprintf("[App/%d]", instance),
+		Message:   text,
+	}.Format()
+}
+
+func messageBodies(messages []syslogdrain.Message) []string {
+	bodies := []string{}
+	for _, message := range messages {
+		bodies = append(bodies, message.Message)
+	}
+	return bodies
+}
+
+var _ = Describe("Listener", func() {
+	var listener *syslogdrain.Listener
+
+	AfterEach(func() {
+		Expect(listener.Close()).To(Succeed())
+	})
+
+	itReceivesStreamedMessages := func(dial func() net.Conn) {
+		It("receives messages in order", func() {
+			conn := dial()
+			defer conn.Close()
+
+			for i := 0; i < 3; i++ {
+				_, err := conn.Write(syslogdrain.Frame(appMessage("app-guid", i, fmt.Sprintf("message %d", i))))
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Eventually(listener.Messages).Should(HaveLen(3))
+			Expect(messageBodies(listener.Messages())).To(Equal([]string{"message 0", "message 1", "message 2"}))
+			Expect(listener.Messages()[2].SourceInstance()).To(Equal("2"))
+			Expect(listener.DidReceive("message 1")).To(BeTrue())
+			Expect(listener.Errors()).To(BeEmpty())
+		})
+
+		It("accepts captured loggregator frames", func() {
+			captured, err := ioutil.ReadFile("testdata/loggregator.frames")
+			Expect(err).NotTo(HaveOccurred())
+
+			conn := dial()
+			defer conn.Close()
+			_, err = conn.Write(captured)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(listener.Messages).Should(HaveLen(4))
+			Expect(listener.MessagesFor("5f7b3c1e-7d4a-4b6e-9a51-0c9f3f1e2d7a")).To(HaveLen(4))
+			Expect(listener.MessagesFor("some-other-app")).To(BeEmpty())
+		})
+
+		It("records messages that do not parse", func() {
+			conn := dial()
+			defer conn.Close()
+
+			_, err := conn.Write([]byte("not a syslog message\n"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(listener.Errors).Should(HaveLen(1))
+			Expect(listener.Messages()).To(BeEmpty())
+		})
+
+		It("closes open connections on Close", func() {
+			conn := dial()
+			defer conn.Close()
+
+			_, err := conn.Write(syslogdrain.Frame(appMessage("app-guid", 0, "hello")))
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(listener.Messages).Should(HaveLen(1))
+
+			Expect(listener.Close()).To(Succeed())
+		})
+
+		It("can be closed more than once", func() {
+			Expect(listener.Close()).To(Succeed())
+			Expect(listener.Close()).To(Succeed())
+		})
+	}
+
+	Context("over TCP", func() {
+		BeforeEach(func() {
+			var err error
+			listener, err = syslogdrain.ListenTCP("127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		itReceivesStreamedMessages(func() net.Conn {
+			conn, err := net.Dial("tcp", listener.Addr().String())
+			Expect(err).NotTo(HaveOccurred())
+			return conn
+		})
+	})
+
+	Context("over TLS", func() {
+		BeforeEach(func() {
+			config, err := syslogdrain.SelfSignedTLSConfig("127.0.0.1")
+			Expect(err).NotTo(HaveOccurred())
+
+			listener, err = syslogdrain.ListenTLS("127.0.0.1:0", config)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		itReceivesStreamedMessages(func() net.Conn {
+			conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+			Expect(err).NotTo(HaveOccurred())
+			return conn
+		})
+	})
+
+	Context("over UDP", func() {
+		BeforeEach(func() {
+			var err error
+			listener, err = syslogdrain.ListenUDP("127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("receives one message per datagram", func() {
+			conn, err := net.Dial("udp", listener.Addr().String())
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			for i := 0; i < 2; i++ {
+				_, err = conn.Write(appMessage("app-guid", 0, fmt.Sprintf("datagram %d", i)))
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Eventually(listener.Messages).Should(HaveLen(2))
+			Expect(messageBodies(listener.Messages())).To(Equal([]string{"datagram 0", "datagram 1"}))
+		})
+	})
+})
@@ -0,0 +1,315 @@
+package syslogdrain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const nilValue = "-"
+
+// Message is a parsed RFC 5424 syslog message. Loggregator sends the app GUID
+// as APP-NAME and the source, e.g. "[App/0]" or "[RTR/1]", as PROCID.
+type Message struct {
+	Priority  int
+	Version   int
+	Timestamp time.Time
+
+	Hostname  string
+	AppName   string
+	ProcessID string
+	MessageID string
+
+	StructuredData map[string]map[string]string
+
+	Message string
+}
+
+func (m Message) Facility() int {
+	return m.Priority / 8
+}
+
+func (m Message) Severity() int {
+	return m.Priority % 8
+}
+
+// AppGuid returns the GUID of the app that emitted the message.
+func (m Message) AppGuid() string {
+	return m.AppName
+}
+
+// SourceType returns the source part of the process ID, e.g. "App" for "[App/0]".
+func (m Message) SourceType() string {
+	source, _ := m.splitProcessID()
+	return source
+}
+
+// SourceInstance returns the instance part of the process ID, e.g. "0" for "[App/0]".
+func (m Message) SourceInstance() string {
+	_, instance := m.splitProcessID()
+	return instance
+}
+
+func (m Message) splitProcessID() (string, string) {
+	id := strings.TrimSuffix(strings.TrimPrefix(m.ProcessID, "["), "]")
+	parts := strings.SplitN(id, "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// Format renders the message in RFC 5424 syntax, without framing.
+func (m Message) Format() []byte {
+	var buffer bytes.Buffer
+
+	timestamp := nilValue
+	if !m.Timestamp.IsZero() {
+		timestamp = m.Timestamp.Format(time.RFC3339Nano)
+	}
+
+	version := m.Version
+	if version == 0 {
+		version = 1
+	}
+
+	fmt.Fprintf(&buffer, "<%d>%d %s %s %s %s %s ",
+		m.Priority, version, timestamp,
+		orNil(m.Hostname), orNil(m.AppName), orNil(m.ProcessID), orNil(m.MessageID))
+
+	if len(m.StructuredData) == 0 {
+		buffer.WriteString(nilValue)
+	} else {
+		ids := []string{}
+		for id := range m.StructuredData {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			buffer.WriteString("[" + id)
+
+			params := m.StructuredData[id]
+			names := []string{}
+			for name := range params {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				fmt.Fprintf(&buffer, ` %s="%s"`, name, escapeParamValue(params[name]))
+			}
+			buffer.WriteString("]")
+		}
+	}
+
+	if m.Message != "" {
+		buffer.WriteString(" " + m.Message)
+	}
+
+	return buffer.Bytes()
+}
+
+func orNil(value string) string {
+	if value == "" {
+		return nilValue
+	}
+	return value
+}
+
+func escapeParamValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
+
+// Parse parses a single RFC 5424 message.
+func Parse(data []byte) (Message, error) {
+	p := &parser{data: data}
+	return p.parse()
+}
+
+type parser struct {
+	data []byte
+	pos  int
+}
+
+func (p *parser) parse() (Message, error) {
+	var m Message
+	var err error
+
+	if m.Priority, err = p.priority(); err != nil {
+		return m, err
+	}
+
+	version, err := p.field("VERSION")
+	if err != nil {
+		return m, err
+	}
+	if m.Version, err = strconv.Atoi(version); err != nil {
+		return m, fmt.Errorf("invalid VERSION '%s'", version)
+	}
+
+	timestamp, err := p.field("TIMESTAMP")
+	if err != nil {
+		return m, err
+	}
+	if timestamp != nilValue {
+		if m.Timestamp, err = time.Parse(time.RFC3339Nano, timestamp); err != nil {
+			return m, fmt.Errorf("invalid TIMESTAMP '%s'", timestamp)
+		}
+	}
+
+	headers := []*string{&m.Hostname, &m.AppName, &m.ProcessID, &m.MessageID}
+	names := []string{"HOSTNAME", "APP-NAME", "PROCID", "MSGID"}
+	for i, header := range headers {
+		value, err := p.field(names[i])
+		if err != nil {
+			return m, err
+		}
+		if value != nilValue {
+			*header = value
+		}
+	}
+
+	if m.StructuredData, err = p.structuredData(); err != nil {
+		return m, err
+	}
+
+	if p.pos < len(p.data) {
+		if p.data[p.pos] != ' ' {
+			return m, fmt.Errorf("expected SP after STRUCTURED-DATA at offset %d", p.pos)
+		}
+		msg := p.data[p.pos+1:]
+		msg = bytes.TrimPrefix(msg, []byte("\xef\xbb\xbf"))
+		m.Message = strings.TrimRight(string(msg), "\r\n")
+	}
+
+	return m, nil
+}
+
+func (p *parser) priority() (int, error) {
+	if p.pos >= len(p.data) || p.data[p.pos] != '<' {
+		return 0, errors.New("expected '<' at the start of PRI")
+	}
+
+	end := bytes.IndexByte(p.data[p.pos:], '>')
+	if end < 2 || end > 4 {
+		return 0, errors.New("invalid PRI")
+	}
+
+	priority, err := strconv.Atoi(string(p.data[p.pos+1 : p.pos+end]))
+	if err != nil || priority > 191 {
+		return 0, fmt.Errorf("invalid PRI '%s'", p.data[p.pos+1:p.pos+end])
+	}
+
+	p.pos += end + 1
+	return priority, nil
+}
+
+// field reads a header field terminated by a single SP.
+func (p *parser) field(name string) (string, error) {
+	end := bytes.IndexByte(p.data[p.pos:], ' ')
+	if end < 1 {
+		return "", fmt.Errorf("missing %s at offset %d", name, p.pos)
+	}
+
+	value := string(p.data[p.pos : p.pos+end])
+	p.pos += end + 1
+	return value, nil
+}
+
+func (p *parser) structuredData() (map[string]map[string]string, error) {
+	if p.pos >= len(p.data) {
+		return nil, errors.New("missing STRUCTURED-DATA")
+	}
+
+	if p.data[p.pos] == '-' {
+		p.pos++
+		return nil, nil
+	}
+
+	data := map[string]map[string]string{}
+	for p.pos < len(p.data) && p.data[p.pos] == '[' {
+		p.pos++
+
+		id := p.name()
+		if id == "" {
+			return nil, fmt.Errorf("missing SD-ID at offset %d", p.pos)
+		}
+		params := map[string]string{}
+
+		for p.pos < len(p.data) && p.data[p.pos] == ' ' {
+			p.pos++
+
+			name := p.name()
+			if name == "" || !p.consume('=') || !p.consume('"') {
+				return nil, fmt.Errorf("invalid SD-PARAM in '%s' at offset %d", id, p.pos)
+			}
+
+			value, err := p.paramValue()
+			if err != nil {
+				return nil, err
+			}
+			params[name] = value
+		}
+
+		if !p.consume(']') {
+			return nil, fmt.Errorf("unterminated SD-ELEMENT '%s'", id)
+		}
+		data[id] = params
+	}
+
+	if len(data) == 0 {
+		return nil, fmt.Errorf("invalid STRUCTURED-DATA at offset %d", p.pos)
+	}
+	return data, nil
+}
+
+func (p *parser) name() string {
+	start := p.pos
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == '=' || c == ' ' || c == ']' || c == '"' {
+			break
+		}
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+func (p *parser) paramValue() (string, error) {
+	var value bytes.Buffer
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+
+		switch c {
+		case '\\':
+			if p.pos < len(p.data) {
+				next := p.data[p.pos]
+				if next == '"' || next == '\\' || next == ']' {
+					value.WriteByte(next)
+					p.pos++
+					continue
+				}
+			}
+			value.WriteByte(c)
+		case '"':
+			return value.String(), nil
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", errors.New("unterminated PARAM-VALUE")
+}
+
+func (p *parser) consume(c byte) bool {
+	if p.pos < len(p.data) && p.data[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
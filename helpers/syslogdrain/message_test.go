@@ -0,0 +1,100 @@
+package syslogdrain_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/syslogdrain"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	It("parses a message as written by loggregator", func() {
+		message, err := syslogdrain.Parse([]byte("<14>1 2015-06-02T18:22:05.123456+00:00 loggregator 5f7b3c1e-7d4a-4b6e-9a51-0c9f3f1e2d7a [App/0] - - Hello from the app"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(message.Priority).To(Equal(14))
+		Expect(message.Facility()).To(Equal(1))
+		Expect(message.Severity()).To(Equal(6))
+		Expect(message.Version).To(Equal(1))
+		Expect(message.Timestamp).To(BeTemporally("==", time.Date(2015, 6, 2, 18, 22, 5, 123456000, time.UTC)))
+		Expect(message.Hostname).To(Equal("loggregator"))
+		Expect(message.AppGuid()).To(Equal("5f7b3c1e-7d4a-4b6e-9a51-0c9f3f1e2d7a"))
+		Expect(message.ProcessID).To(Equal("[App/0]"))
+		Expect(message.SourceType()).To(Equal("App"))
+		Expect(message.SourceInstance()).To(Equal("0"))
+		Expect(message.MessageID).To(BeEmpty())
+		Expect(message.StructuredData).To(BeNil())
+		Expect(message.Message).To(Equal("Hello from the app"))
+	})
+
+	It("parses structured data, unescaping param values", func() {
+		message, err := syslogdrain.Parse([]byte(`<165>1 2003-10-11T22:14:15.003Z host app 8710 ID47 [exampleSDID@32473 iut="3" eventSource="App\"lication\]"][other@1 a="b\\c"] An application event`))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(message.MessageID).To(Equal("ID47"))
+		Expect(message.SourceType()).To(Equal("8710"))
+		Expect(message.SourceInstance()).To(BeEmpty())
+		Expect(message.StructuredData).To(Equal(map[string]map[string]string{
+			"exampleSDID@32473": {"iut": "3", "eventSource": `App"lication]`},
+			"other@1":           {"a": `b\c`},
+		}))
+		Expect(message.Message).To(Equal("An application event"))
+	})
+
+	It("strips a BOM and trailing newline from the message", func() {
+		message, err := syslogdrain.Parse([]byte("<14>1 - - - - - - \xef\xbb\xbfhello\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(message.Timestamp.IsZero()).To(BeTrue())
+		Expect(message.AppName).To(BeEmpty())
+		Expect(message.Message).To(Equal("hello"))
+	})
+
+	It("allows the message to be omitted", func() {
+		message, err := syslogdrain.Parse([]byte("<14>1 - host app - - -"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(message.Message).To(BeEmpty())
+	})
+
+	itRejects := func(description string, data string, errorText string) {
+		It("rejects "+description, func() {
+			_, err := syslogdrain.Parse([]byte(data))
+			Expect(err).To(MatchError(ContainSubstring(errorText)))
+		})
+	}
+
+	itRejects("raw text", "random-message-1234", "expected '<'")
+	itRejects("an out of range priority", "<192>1 - - - - - -", "invalid PRI")
+	itRejects("a non-numeric version", "<14>x - - - - - -", "invalid VERSION")
+	itRejects("a bad timestamp", "<14>1 yesterday - - - - -", "invalid TIMESTAMP")
+	itRejects("truncated headers", "<14>1 - host app ", "missing PROCID")
+	itRejects("missing structured data", "<14>1 - host app - - ", "missing STRUCTURED-DATA")
+	itRejects("an unterminated SD element", `<14>1 - host app - - [id a="b"`, "unterminated SD-ELEMENT")
+	itRejects("an unterminated param value", `<14>1 - host app - - [id a="b]`, "unterminated PARAM-VALUE")
+	itRejects("junk after the structured data", `<14>1 - host app - - [id]x`, "expected SP")
+})
+
+var _ = Describe("Message", func() {
+	It("formats messages that parse back to the same value", func() {
+		original := syslogdrain.Message{
+			Priority:       14,
+			Version:        1,
+			Timestamp:      time.Date(2015, 6, 2, 18, 22, 5, 123456000, time.UTC),
+			Hostname:       "loggregator",
+			AppName:        "app-guid",
+			ProcessID:      "[App/3]",
+			StructuredData: map[string]map[string]string{"id@1": {"key": `quoted "value"`}},
+			Message:        "hello world",
+		}
+
+		parsed, err := syslogdrain.Parse(original.Format())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(Equal(original))
+	})
+
+	It("uses the nil value for empty fields", func() {
+		Expect(string(syslogdrain.Message{Priority: 14, Message: "hi"}.Format())).To(Equal("<14>1 - - - - - - hi"))
+	})
+})
@@ -0,0 +1,13 @@
+package syslogdrain_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSyslogdrain(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Syslog Drain Suite")
+}
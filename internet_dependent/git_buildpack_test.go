@@ -9,6 +9,7 @@ import (
 	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
 	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/push"
 )
 
 var _ = Describe("GitBuildpack", func() {
@@ -18,7 +19,9 @@ var _ = Describe("GitBuildpack", func() {
 
 	It("uses a buildpack from a git url", func() {
 		appName = generator.RandomName()
-		Expect(cf.Cf("push", appName, "-p", assets.NewAssets().Node, "-c", "node app.js", "-b", "https://github.com/cloudfoundry/nodejs-buildpack.git").Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
+		Expect(push.Push(appName, assets.NewAssets().Node, push.Options{
+			Buildpack: "https://github.com/cloudfoundry/nodejs-buildpack.git",
+		}).Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
 
 		Eventually(func() string {
 			return helpers.CurlAppRoot(appName)
@@ -8,6 +8,9 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+	catsconfig "github.com/cloudfoundry/cf-acceptance-tests/helpers/config"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/suite"
 )
 
 var (
@@ -16,12 +19,14 @@ var (
 	LONG_CURL_TIMEOUT = 2 * time.Minute
 )
 
-var context helpers.SuiteContext
+var context suite.SuiteContext
 
 func TestLogging(t *testing.T) {
 	RegisterFailHandler(Fail)
 
-	config := helpers.LoadConfig()
+	config := catsconfig.Load("logging")
+	defer config.RemoveEffectiveConfig()
+	config.SkipSuiteUnless(t, "include_logging")
 
 	if config.DefaultTimeout > 0 {
 		DEFAULT_TIMEOUT = config.DefaultTimeout * time.Second
@@ -35,8 +40,9 @@ func TestLogging(t *testing.T) {
 		LONG_CURL_TIMEOUT = config.LongCurlTimeout * time.Second
 	}
 
-	context = helpers.NewContext(config)
+	context = suite.NewContext(config.Config)
 	environment := helpers.NewEnvironment(context)
+	cleanup.Install(context.AdminUserContext())
 
 	BeforeSuite(func() {
 		environment.Setup()
@@ -51,9 +57,11 @@ func TestLogging(t *testing.T) {
 	rs := []Reporter{}
 
 	if config.ArtifactsDirectory != "" {
-		helpers.EnableCFTrace(config, componentName)
-		rs = append(rs, helpers.NewJUnitReporter(config, componentName))
+		helpers.EnableCFTrace(config.Config, componentName)
+		rs = append(rs, helpers.NewJUnitReporter(config.Config, componentName))
 	}
 
+	config.SkipExcludedSpecs()
+
 	RunSpecsWithDefaultAndCustomReporters(t, componentName, rs)
 }
@@ -1,50 +1,66 @@
 package logging
 
 import (
+	"crypto/tls"
 	"fmt"
-	"io"
 	"net"
 	"net/http"
-	"strings"
-	"sync"
+	"time"
 
 	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
 	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
 	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/syslogdrain"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gexec"
 )
 
 var _ = Describe("Logging", func() {
-	var testConfig = helpers.LoadConfig()
-	var appName string
+	var testConfig helpers.Config
 
 	Describe("Syslog drains", func() {
-		var drainListener *syslogDrainListener
+		var syslogDrainAddress string
+		var drainListener *syslogdrain.Listener
+		var appName string
+		var appGuid string
 		var serviceName string
-		var appURL string
-
-		BeforeEach(func() {
-			syslogDrainAddress := fmt.Sprintf("%s:%d", testConfig.SyslogIpAddress, testConfig.SyslogDrainPort)
-
-			drainListener = &syslogDrainListener{port: testConfig.SyslogDrainPort}
-			drainListener.StartListener()
-			go drainListener.AcceptConnections()
-
-			testThatDrainIsReachable(syslogDrainAddress, drainListener)
 
+		pushAppWithDrain := func(scheme string) {
 			appName = generator.RandomName()
-			appURL = appName + "." + testConfig.AppsDomain
-
 			Eventually(cf.Cf("push", appName, "-p", assets.NewAssets().RubySimple), CF_PUSH_TIMEOUT).Should(Exit(0), "Failed to push app")
+			appGuid = ccapi.FindApp(appName).Metadata.Guid
 
-			syslogDrainURL := "syslog://" + syslogDrainAddress
 			serviceName = "service-" + generator.RandomName()
-
-			Eventually(cf.Cf("cups", serviceName, "-l", syslogDrainURL), DEFAULT_TIMEOUT).Should(Exit(0), "Failed to create syslog drain service")
+			Eventually(cf.Cf("cups", serviceName, "-l", scheme+"://"+syslogDrainAddress), DEFAULT_TIMEOUT).Should(Exit(0), "Failed to create syslog drain service")
 			Eventually(cf.Cf("bind-service", appName, serviceName), DEFAULT_TIMEOUT).Should(Exit(0), "Failed to bind service")
+		}
+
+		appLogsTo := func(message string) []syslogdrain.Message {
+			appLogs := []syslogdrain.Message{}
+			for _, received := range drainListener.MessagesFor(appGuid) {
+				if received.SourceType() == "App" && received.Message == message {
+					appLogs = append(appLogs, received)
+				}
+			}
+			return appLogs
+		}
+
+		logFromApp := func(message string) func() []syslogdrain.Message {
+			return func() []syslogdrain.Message {
+				http.Get("http://" + appName + "." + testConfig.AppsDomain + "/log/" + message)
+				return appLogsTo(message)
+			}
+		}
+
+		BeforeEach(func() {
+			// delete-orphaned-routes in the AfterEach would otherwise remove
+			// routes that other specs on the node are about to map.
+			context.IsolateSpace()
+			testConfig = helpers.LoadConfig()
+			syslogDrainAddress = fmt.Sprintf("%s:%d", testConfig.SyslogIpAddress, testConfig.SyslogDrainPort)
 		})
 
 		AfterEach(func() {
@@ -55,81 +71,87 @@ var _ = Describe("Logging", func() {
 
 			Eventually(cf.Cf("delete-orphaned-routes", "-f"), CF_PUSH_TIMEOUT).Should(Exit(0), "Failed to delete orphaned routes")
 
-			drainListener.Stop()
+			if drainListener != nil {
+				Expect(drainListener.Close()).To(Succeed())
+				drainListener = nil
+			}
 		})
 
-		It("forwards app messages to registered syslog drains", func() {
-			randomMessage := "random-message-" + generator.RandomName()
-
-			Eventually(func() bool {
-				http.Get("http://" + appURL + "/log/" + randomMessage)
-				return drainListener.DidReceive(randomMessage)
-			}, 90, 1).Should(BeTrue(), "Never received "+randomMessage+" on syslog drain listener")
+		Context("with a syslog:// drain", func() {
+			BeforeEach(func() {
+				var err error
+				drainListener, err = syslogdrain.ListenTCP(fmt.Sprintf(":%d", testConfig.SyslogDrainPort))
+				Expect(err).ToNot(HaveOccurred())
+
+				testThatDrainIsReachable(drainListener, func() (net.Conn, error) {
+					return net.Dial("tcp", syslogDrainAddress)
+				})
+
+				pushAppWithDrain("syslog")
+			})
+
+			It("forwards app messages to registered syslog drains", func() {
+				randomMessage := "random-message-" + generator.RandomName()
+
+				Eventually(logFromApp(randomMessage), 90*time.Second, time.Second).ShouldNot(BeEmpty(), "Never received "+randomMessage+" on syslog drain listener")
+
+				received := appLogsTo(randomMessage)[0]
+				Expect(received.AppGuid()).To(Equal(appGuid))
+				Expect(received.SourceInstance()).To(Equal("0"))
+				Expect(received.Timestamp).To(BeTemporally("~", time.Now(), 5*time.Minute))
+			})
+
+			It("forwards messages in the order the app logged them", func() {
+				firstMessage := "first-message-" + generator.RandomName()
+				Eventually(logFromApp(firstMessage), 90*time.Second, time.Second).ShouldNot(BeEmpty(), "Never received "+firstMessage+" on syslog drain listener")
+
+				secondMessage := "second-message-" + generator.RandomName()
+				Eventually(logFromApp(secondMessage), 90*time.Second, time.Second).ShouldNot(BeEmpty(), "Never received "+secondMessage+" on syslog drain listener")
+
+				bodies := []string{}
+				for _, received := range drainListener.MessagesFor(appGuid) {
+					if received.Message == firstMessage || received.Message == secondMessage {
+						bodies = append(bodies, received.Message)
+					}
+				}
+				Expect(bodies[0]).To(Equal(firstMessage))
+				Expect(bodies[len(bodies)-1]).To(Equal(secondMessage))
+			})
 		})
-	})
-})
 
-type syslogDrainListener struct {
-	sync.Mutex
-	port             int
-	listener         net.Listener
-	receivedMessages string
-}
+		Context("with a syslog-tls:// drain", func() {
+			BeforeEach(func() {
+				tlsConfig, err := syslogdrain.SelfSignedTLSConfig(testConfig.SyslogIpAddress)
+				Expect(err).ToNot(HaveOccurred())
 
-func (s *syslogDrainListener) StartListener() {
-	listenAddress := fmt.Sprintf(":%d", s.port)
-	var err error
-	s.listener, err = net.Listen("tcp", listenAddress)
-	Expect(err).ToNot(HaveOccurred())
-}
+				drainListener, err = syslogdrain.ListenTLS(fmt.Sprintf(":%d", testConfig.SyslogDrainPort), tlsConfig)
+				Expect(err).ToNot(HaveOccurred())
 
-func (s *syslogDrainListener) AcceptConnections() {
-	defer GinkgoRecover()
+				testThatDrainIsReachable(drainListener, func() (net.Conn, error) {
+					return tls.Dial("tcp", syslogDrainAddress, &tls.Config{InsecureSkipVerify: true})
+				})
 
-	for {
-		conn, err := s.listener.Accept()
-		if err != nil {
-			return
-		}
-		go s.handleConnection(conn)
-	}
-}
-
-func (s *syslogDrainListener) Stop() {
-	s.listener.Close()
-}
+				pushAppWithDrain("syslog-tls")
+			})
 
-func (s *syslogDrainListener) DidReceive(message string) bool {
-	s.Lock()
-	defer s.Unlock()
+			It("forwards app messages over TLS", func() {
+				randomMessage := "random-message-" + generator.RandomName()
 
-	return strings.Contains(s.receivedMessages, message)
-}
-
-func (s *syslogDrainListener) handleConnection(conn net.Conn) {
-	defer GinkgoRecover()
-	buffer := make([]byte, 65536)
-	for {
-		n, err := conn.Read(buffer)
-
-		if err == io.EOF {
-			return
-		}
-		Expect(err).ToNot(HaveOccurred())
-
-		s.Lock()
-		s.receivedMessages += string(buffer[0:n])
-		s.Unlock()
-	}
-}
+				Eventually(logFromApp(randomMessage), 90*time.Second, time.Second).ShouldNot(BeEmpty(), "Never received "+randomMessage+" on syslog-tls drain listener")
+				Expect(appLogsTo(randomMessage)[0].AppGuid()).To(Equal(appGuid))
+			})
+		})
+	})
+})
 
-func testThatDrainIsReachable(syslogDrainAddress string, drainListener *syslogDrainListener) {
-	conn, err := net.Dial("tcp", syslogDrainAddress)
+func testThatDrainIsReachable(drainListener *syslogdrain.Listener, dial func() (net.Conn, error)) {
+	conn, err := dial()
 	Expect(err).ToNot(HaveOccurred())
 	defer conn.Close()
 
 	randomMessage := "random-message-" + generator.RandomName()
-	_, err = conn.Write([]byte(randomMessage))
+	message := syslogdrain.Message{Priority: 14, Timestamp: time.Now(), Hostname: "cats", Message: randomMessage}
+	_, err = conn.Write(syslogdrain.Frame(message.Format()))
 	Expect(err).ToNot(HaveOccurred())
 
 	Eventually(func() bool {
@@ -3,8 +3,8 @@ package operator
 import (
 	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
 	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
-	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/matchers"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -25,8 +25,6 @@ var _ = Describe("FUSE", func() {
 	It("Can mount a fuse endpoint", func() {
 		Expect(cf.Cf("push", appName, "-p", assets.NewAssets().Fuse).Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
 
-		Eventually(func() string {
-			return helpers.CurlAppRoot(appName)
-		}, DEFAULT_TIMEOUT).Should(ContainSubstring("great success!"))
+		Eventually(appClient.Probing(appName, "/"), DEFAULT_TIMEOUT).Should(BeReachableWithBody(ContainSubstring("great success!")))
 	})
 })
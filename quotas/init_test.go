@@ -0,0 +1,63 @@
+package quotas
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+	catsconfig "github.com/cloudfoundry/cf-acceptance-tests/helpers/config"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/suite"
+)
+
+var (
+	DEFAULT_TIMEOUT = 30 * time.Second
+	CF_PUSH_TIMEOUT = 2 * time.Minute
+)
+
+var context suite.SuiteContext
+var config catsconfig.Config
+
+func TestQuotas(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	config = catsconfig.Load("quotas")
+	defer config.RemoveEffectiveConfig()
+	config.SkipSuiteUnless(t, "include_quotas")
+
+	if config.DefaultTimeout > 0 {
+		DEFAULT_TIMEOUT = config.DefaultTimeout * time.Second
+	}
+
+	if config.CfPushTimeout > 0 {
+		CF_PUSH_TIMEOUT = config.CfPushTimeout * time.Second
+	}
+
+	context = suite.NewContext(config.Config)
+	environment := helpers.NewEnvironment(context)
+	cleanup.Install(context.AdminUserContext())
+
+	BeforeSuite(func() {
+		environment.Setup()
+	})
+
+	AfterSuite(func() {
+		environment.Teardown()
+	})
+
+	componentName := "Quotas"
+
+	rs := []Reporter{}
+
+	if config.ArtifactsDirectory != "" {
+		helpers.EnableCFTrace(config.Config, componentName)
+		rs = append(rs, helpers.NewJUnitReporter(config.Config, componentName))
+	}
+
+	config.SkipExcludedSpecs()
+
+	RunSpecsWithDefaultAndCustomReporters(t, componentName, rs)
+}
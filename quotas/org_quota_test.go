@@ -0,0 +1,42 @@
+package quotas
+
+import (
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/push"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/suite"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Org quotas", func() {
+	// The node's org keeps its generous quota, so these specs get an org of
+	// their own, which only the admin can create.
+	It("stops apps from starting with more memory than the org has", func() {
+		orgName := "CATS-ORG-" + generator.RandomName()
+		spaceName := "CATS-SPACE-" + generator.RandomName()
+		quota := suite.DefaultQuota()
+		quota.Memory = "64M"
+
+		cf.AsUser(context.AdminUserContext(), func() {
+			suite.CreateOrgQuota(quota)
+
+			Expect(cf.Cf("create-org", orgName).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+			cleanup.Current().Org(orgName)
+			Expect(cf.Cf("set-quota", orgName, quota.Name).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+
+			// The space goes when the org is deleted; delete-org recurses.
+			Expect(cf.Cf("create-space", "-o", orgName, spaceName).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+			Expect(cf.Cf("target", "-o", orgName, "-s", spaceName).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+
+			pushApp := push.Push(generator.RandomName(), assets.NewAssets().Dora, push.Options{Memory: "128M"}).Wait(CF_PUSH_TIMEOUT)
+			Expect(pushApp).To(Exit(1))
+			Expect(pushApp).To(Say("memory limit"))
+		})
+	})
+})
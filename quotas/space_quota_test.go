@@ -0,0 +1,68 @@
+package quotas
+
+import (
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/push"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/suite"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Space quotas", func() {
+	var (
+		appName string
+		quota   suite.Quota
+	)
+
+	BeforeEach(func() {
+		context.IsolateSpace()
+		appName = generator.RandomName()
+		quota = suite.DefaultQuota()
+	})
+
+	It("stops apps from starting with more memory than the space has", func() {
+		quota.Memory = "64M"
+		context.ApplySpaceQuota(quota)
+
+		pushApp := push.Push(appName, assets.NewAssets().Dora, push.Options{Memory: "128M"}).Wait(CF_PUSH_TIMEOUT)
+		Expect(pushApp).To(Exit(1))
+		Expect(pushApp).To(Say("memory limit"))
+	})
+
+	It("stops apps from scaling beyond the memory the space has", func() {
+		quota.Memory = "256M"
+		context.ApplySpaceQuota(quota)
+
+		Expect(push.Push(appName, assets.NewAssets().Dora, push.Options{Memory: "128M"}).Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
+
+		scale := cf.Cf("scale", appName, "-i", "3").Wait(DEFAULT_TIMEOUT)
+		Expect(scale).To(Exit(1))
+		Expect(scale).To(Say("memory limit"))
+	})
+
+	It("stops instances from using more memory than an instance may have", func() {
+		quota.InstanceMemory = 64
+		context.ApplySpaceQuota(quota)
+
+		pushApp := push.Push(appName, assets.NewAssets().Dora, push.Options{Memory: "128M"}).Wait(CF_PUSH_TIMEOUT)
+		Expect(pushApp).To(Exit(1))
+		Expect(pushApp).To(Say("instance memory limit"))
+	})
+
+	It("stops routes from being created beyond the space's total", func() {
+		quota.Routes = 1
+		context.ApplySpaceQuota(quota)
+
+		Expect(push.Push(appName, assets.NewAssets().Dora, push.Options{NoStart: true}).Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
+
+		space := context.RegularUserContext().Space
+		createRoute := cf.Cf("create-route", space, config.AppsDomain, "-n", generator.RandomName()).Wait(DEFAULT_TIMEOUT)
+		Expect(createRoute).To(Exit(1))
+		Expect(createRoute).To(Say("total routes"))
+	})
+})
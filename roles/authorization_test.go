@@ -0,0 +1,123 @@
+package roles
+
+import (
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/push"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/suite"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const notAuthorized = "not authorized"
+
+var _ = Describe("Role authorization", func() {
+	var appName string
+
+	pushAs := func(role suite.Role) *Session {
+		var session *Session
+		cf.AsUser(context.UserWithRole(role), func() {
+			session = push.Push(appName, assets.NewAssets().Dora, push.Options{NoStart: true}).Wait(CF_PUSH_TIMEOUT)
+		})
+		return session
+	}
+
+	BeforeEach(func() {
+		appName = generator.RandomName()
+	})
+
+	Describe("a space developer", func() {
+		It("can push apps", func() {
+			Expect(pushAs(suite.SpaceDeveloper)).To(Exit(0))
+		})
+
+		It("cannot change the roles of the space's users", func() {
+			auditor := context.UserWithRole(suite.SpaceAuditor)
+
+			cf.AsUser(context.UserWithRole(suite.SpaceDeveloper), func() {
+				setRole := cf.Cf("set-space-role", auditor.Username, auditor.Org, auditor.Space, string(suite.SpaceDeveloper)).Wait(DEFAULT_TIMEOUT)
+				Expect(setRole).To(Exit(1))
+				Expect(setRole).To(Say(notAuthorized))
+			})
+		})
+	})
+
+	Describe("a space auditor", func() {
+		It("cannot push apps", func() {
+			pushApp := pushAs(suite.SpaceAuditor)
+			Expect(pushApp).To(Exit(1))
+			Expect(pushApp).To(Say(notAuthorized))
+		})
+
+		It("can see the space's apps", func() {
+			Expect(pushAs(suite.SpaceDeveloper)).To(Exit(0))
+
+			cf.AsUser(context.UserWithRole(suite.SpaceAuditor), func() {
+				Expect(cf.Cf("app", appName).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+			})
+		})
+
+		It("cannot delete the space's apps", func() {
+			Expect(pushAs(suite.SpaceDeveloper)).To(Exit(0))
+
+			cf.AsUser(context.UserWithRole(suite.SpaceAuditor), func() {
+				deleteApp := cf.Cf("delete", appName, "-f").Wait(DEFAULT_TIMEOUT)
+				Expect(deleteApp).To(Exit(1))
+				Expect(deleteApp).To(Say(notAuthorized))
+			})
+		})
+	})
+
+	Describe("an org manager", func() {
+		It("can change the roles of the org's users", func() {
+			auditor := context.UserWithRole(suite.SpaceAuditor)
+
+			cf.AsUser(context.UserWithRole(suite.OrgManager), func() {
+				Expect(cf.Cf("set-space-role", auditor.Username, auditor.Org, auditor.Space, string(suite.SpaceDeveloper)).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+			})
+			cleanup.Current().SpaceRole(auditor.Username, auditor.Org, auditor.Space, string(suite.SpaceDeveloper))
+
+			Expect(pushAs(suite.SpaceAuditor)).To(Exit(0))
+		})
+
+		It("can create and delete spaces", func() {
+			manager := context.UserWithRole(suite.OrgManager)
+			spaceName := "CATS-SPACE-" + generator.RandomName()
+
+			cf.AsUser(manager, func() {
+				Expect(cf.Cf("create-space", spaceName).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+				cleanup.Current().Space(manager.Org, spaceName)
+
+				Expect(cf.Cf("delete-space", spaceName, "-f").Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+				cleanup.Current().SpaceDeleted(manager.Org, spaceName)
+			})
+		})
+	})
+
+	Describe("an org auditor", func() {
+		It("cannot create spaces", func() {
+			cf.AsUser(context.UserWithRole(suite.OrgAuditor), func() {
+				createSpace := cf.Cf("create-space", "CATS-SPACE-"+generator.RandomName()).Wait(DEFAULT_TIMEOUT)
+				Expect(createSpace).To(Exit(1))
+				Expect(createSpace).To(Say(notAuthorized))
+			})
+		})
+	})
+
+	Describe("a billing manager", func() {
+		It("cannot change the roles of the org's users", func() {
+			auditor := context.UserWithRole(suite.OrgAuditor)
+
+			cf.AsUser(context.UserWithRole(suite.BillingManager), func() {
+				setRole := cf.Cf("set-org-role", auditor.Username, auditor.Org, string(suite.OrgManager)).Wait(DEFAULT_TIMEOUT)
+				Expect(setRole).To(Exit(1))
+				Expect(setRole).To(Say(notAuthorized))
+			})
+		})
+	})
+})
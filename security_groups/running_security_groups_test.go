@@ -14,29 +14,14 @@ import (
 
 	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
 	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
-	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/push"
 )
 
 var _ = Describe("Security Groups", func() {
 
-	type AppResource struct {
-		Metadata struct {
-			Url string
-		}
-	}
-	type AppsResponse struct {
-		Resources []AppResource
-	}
-
-	type Stat struct {
-		Stats struct {
-			Host string
-			Port int
-		}
-	}
-	type StatsResponse map[string]Stat
-
 	type DoraCurlResponse struct {
 		Stdout     string
 		Stderr     string
@@ -48,25 +33,16 @@ var _ = Describe("Security Groups", func() {
 
 	BeforeEach(func() {
 		serverAppName = generator.RandomName()
+		cleanup.Current().App(serverAppName)
 		Expect(cf.Cf("push", serverAppName, "-p", assets.NewAssets().Dora).Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
 
-		// gather app url
-		var appsResponse AppsResponse
-		cfResponse := cf.Cf("curl", fmt.Sprintf("/v2/apps?q=name:%s", serverAppName)).Wait(DEFAULT_TIMEOUT).Out.Contents()
-		json.Unmarshal(cfResponse, &appsResponse)
-		serverAppUrl := appsResponse.Resources[0].Metadata.Url
-
 		// gather app stats for dea ip and app port
-		var statsResponse StatsResponse
-		cfResponse = cf.Cf("curl", fmt.Sprintf("%s/stats", serverAppUrl)).Wait(DEFAULT_TIMEOUT).Out.Contents()
-		json.Unmarshal(cfResponse, &statsResponse)
-
-		privateHost = statsResponse["0"].Stats.Host
-		privatePort = statsResponse["0"].Stats.Port
-	})
+		serverAppGuid := ccapi.FindApp(serverAppName).Metadata.Guid
+		stats := ccapi.GetAppStats(serverAppGuid)
+		Expect(stats).To(HaveKey("0"))
 
-	AfterEach(func() {
-		Expect(cf.Cf("delete", serverAppName, "-f").Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
+		privateHost = stats["0"].Stats.Host
+		privatePort = stats["0"].Stats.Port
 	})
 
 	// this test assumes the default running security groups block access to the DEAs
@@ -74,16 +50,16 @@ var _ = Describe("Security Groups", func() {
 	//  are discoverable via the cc api and dora's myip endpoint
 	It("allows previously-blocked ip traffic after applying a security group, and re-blocks it when the group is removed", func() {
 		clientAppName := generator.RandomName()
+		cleanup.Current().App(clientAppName)
 		Expect(cf.Cf("push", clientAppName, "-p", assets.NewAssets().Dora).Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
-		defer func() { cf.Cf("delete", clientAppName, "-f").Wait(CF_PUSH_TIMEOUT) }()
 
 		// gather container ip
-		curlResponse := helpers.CurlApp(serverAppName, "/myip")
+		curlResponse := appClient.Body(serverAppName, "/myip")
 		containerIp := strings.TrimSpace(curlResponse)
 
 		// test app egress rules
 		var doraCurlResponse DoraCurlResponse
-		curlResponse = helpers.CurlApp(clientAppName, fmt.Sprintf("/curl/%s/%d", privateHost, privatePort))
+		curlResponse = appClient.Body(clientAppName, fmt.Sprintf("/curl/%s/%d", privateHost, privatePort))
 		json.Unmarshal([]byte(curlResponse), &doraCurlResponse)
 		Expect(doraCurlResponse.ReturnCode).ToNot(Equal(0))
 
@@ -102,33 +78,31 @@ var _ = Describe("Security Groups", func() {
 
 		cf.AsUser(context.AdminUserContext(), func() {
 			Expect(cf.Cf("create-security-group", securityGroupName, rulesPath).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+			cleanup.Current().SecurityGroup(securityGroupName)
 			Expect(
 				cf.Cf("bind-security-group",
 					securityGroupName,
 					context.RegularUserContext().Org,
 					context.RegularUserContext().Space).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+			cleanup.Current().SecurityGroupBinding(securityGroupName, context.RegularUserContext().Org, context.RegularUserContext().Space)
 		})
-		defer func() {
-			cf.AsUser(context.AdminUserContext(), func() {
-				Expect(cf.Cf("delete-security-group", securityGroupName, "-f").Wait(DEFAULT_TIMEOUT)).To(Exit(0))
-			})
-		}()
 
 		Expect(cf.Cf("restart", clientAppName).Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
 
 		// test app egress rules
-		curlResponse = helpers.CurlApp(clientAppName, fmt.Sprintf("/curl/%s/%d", privateHost, privatePort))
+		curlResponse = appClient.Body(clientAppName, fmt.Sprintf("/curl/%s/%d", privateHost, privatePort))
 		json.Unmarshal([]byte(curlResponse), &doraCurlResponse)
 		Expect(doraCurlResponse.ReturnCode).To(Equal(0))
 
 		// unapply security group
 		cf.AsUser(context.AdminUserContext(), func() {
 			Expect(cf.Cf("unbind-security-group", securityGroupName, context.RegularUserContext().Org, context.RegularUserContext().Space).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+			cleanup.Current().SecurityGroupUnbound(securityGroupName, context.RegularUserContext().Org, context.RegularUserContext().Space)
 		})
 		Expect(cf.Cf("restart", clientAppName).Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
 
 		// test app egress rules
-		curlResponse = helpers.CurlApp(clientAppName, fmt.Sprintf("/curl/%s/%d", privateHost, privatePort))
+		curlResponse = appClient.Body(clientAppName, fmt.Sprintf("/curl/%s/%d", privateHost, privatePort))
 		json.Unmarshal([]byte(curlResponse), &doraCurlResponse)
 		Expect(doraCurlResponse.ReturnCode).ToNot(Equal(0))
 	})
@@ -143,14 +117,9 @@ var _ = Describe("Security Groups", func() {
 		cf.AsUser(context.AdminUserContext(), func() {
 			Expect(cf.Cf("create-buildpack", buildpack, buildpackZip, "999").Wait(DEFAULT_TIMEOUT)).To(Exit(0))
 		})
-		defer func() {
-			cf.AsUser(context.AdminUserContext(), func() {
-				Expect(cf.Cf("delete-buildpack", buildpack, "-f").Wait(DEFAULT_TIMEOUT)).To(Exit(0))
-			})
-		}()
-
-		Expect(cf.Cf("push", testAppName, "-b", buildpack, "-p", assets.NewAssets().HelloWorld, "--no-start").Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
-		defer func() { cf.Cf("delete", testAppName, "-f").Wait(CF_PUSH_TIMEOUT) }()
+		cleanup.Current().Buildpack(buildpack)
+
+		Expect(push.Push(testAppName, assets.NewAssets().HelloWorld, push.Options{Buildpack: buildpack, NoStart: true}).Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
 
 		Expect(cf.Cf("set-env", testAppName, "TESTURI", "www.google.com").Wait(DEFAULT_TIMEOUT)).To(Exit(0))
 		Expect(cf.Cf("start", testAppName).Wait(CF_PUSH_TIMEOUT)).To(Exit(1))
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
+	catsconfig "github.com/cloudfoundry/cf-acceptance-tests/helpers/config"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakebroker"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Async service operations", func() {
+	var broker ServiceBroker
+	var instanceName string
+	var instanceGuid string
+
+	lastOperation := func() ccapi.LastOperation {
+		return ccapi.GetServiceInstance(instanceGuid).Entity.LastOperation
+	}
+
+	inState := func(state string) func(ccapi.LastOperation) bool {
+		return func(operation ccapi.LastOperation) bool {
+			return operation.State == state
+		}
+	}
+
+	serviceInfo := func() *Session {
+		return cf.Cf("service", instanceName).Wait(DEFAULT_TIMEOUT)
+	}
+
+	createBroker := func(outcomes map[fakebroker.Operation]AsyncOutcome) {
+		broker = NewServiceBroker(randomBrokerName(), assets.NewAssets().AsyncServiceBroker, context)
+		broker.LastOperations = outcomes
+		broker.Push()
+		broker.Configure()
+		broker.Create()
+		broker.PublicizePlans()
+	}
+
+	// createService doesn't record the instance for cleanup: the Cloud
+	// Controller refuses to delete it while an operation is in progress, but
+	// purging the broker's offering after the spec removes it either way.
+	createService := func() {
+		ExpectWithOffset(1, cf.Cf("create-service", broker.Service.Name, broker.Plans[0].Name, instanceName).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+		instanceGuid = ccapi.FindServiceInstance(instanceName).Metadata.Guid
+	}
+
+	BeforeEach(func() {
+		context.IsolateSpace()
+		instanceName = generator.RandomName()
+	})
+
+	Context("when provisioning fails", func() {
+		BeforeEach(func() {
+			createBroker(map[fakebroker.Operation]AsyncOutcome{
+				fakebroker.Provision: {State: "failed", Description: "out of disks"},
+			})
+			createService()
+		})
+
+		It("reports the broker's description of the failure", func() {
+			operation := waitForLastOperation(instanceGuid, ccapi.LastOperation.Finished)
+			Expect(operation.Type).To(Equal("create"))
+			Expect(operation.State).To(Equal("failed"))
+			Expect(operation.Description).To(Equal("out of disks"))
+
+			info := serviceInfo()
+			Expect(info).To(Exit(0))
+			Expect(info).To(Say("create failed"))
+			Expect(info).To(Say("out of disks"))
+		})
+	})
+
+	Context("when the broker never finishes provisioning", func() {
+		BeforeEach(func() {
+			createBroker(map[fakebroker.Operation]AsyncOutcome{
+				fakebroker.Provision: {State: "in progress", Description: "still provisioning"},
+			})
+			createService()
+		})
+
+		It("keeps the operation in progress", func() {
+			operation := waitForLastOperation(instanceGuid, func(operation ccapi.LastOperation) bool {
+				return operation.Description == "still provisioning"
+			})
+			Expect(operation.Type).To(Equal("create"))
+			Expect(operation.State).To(Equal("in progress"))
+
+			info := serviceInfo()
+			Expect(info).To(Exit(0))
+			Expect(info).To(Say("create in progress"))
+			Expect(info).To(Say("still provisioning"))
+		})
+
+		Context(catsconfig.Tag("include_async_poll_expiry"), func() {
+			It("fails the operation once the Cloud Controller stops polling", func() {
+				operation, err := ccapi.WaitForLastOperation(instanceGuid, BROKER_MAX_ASYNC_POLL_DURATION+ASYNC_SERVICE_OPERATION_TIMEOUT, ccapi.LastOperation.Finished)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(operation.Type).To(Equal("create"))
+				Expect(operation.State).To(Equal("failed"))
+				Expect(operation.Description).NotTo(BeEmpty())
+
+				info := serviceInfo()
+				Expect(info).To(Exit(0))
+				Expect(info).To(Say("create failed"))
+			})
+		})
+	})
+
+	Context("when the instance is gone while deprovisioning", func() {
+		BeforeEach(func() {
+			createBroker(map[fakebroker.Operation]AsyncOutcome{
+				fakebroker.Deprovision: {Status: 410},
+			})
+			createService()
+			waitForLastOperation(instanceGuid, inState("succeeded"))
+		})
+
+		It("deletes the instance", func() {
+			Expect(cf.Cf("delete-service", instanceName, "-f").Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+			operation := lastOperation()
+			Expect(operation.Type).To(Equal("delete"))
+			Expect(operation.State).To(Equal("in progress"))
+
+			Eventually(serviceInfo, ASYNC_SERVICE_OPERATION_TIMEOUT, 15*time.Second).Should(Say("not found"))
+		})
+	})
+})
@@ -2,7 +2,8 @@ package services
 
 import (
 	"encoding/json"
-	"fmt"
+	"io/ioutil"
+	"os"
 
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gbytes"
@@ -11,11 +12,27 @@ import (
 	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
 	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
 	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakebroker"
 )
 
 type Plan struct {
 	Name string `json:"name"`
 	ID   string `json:"id"`
+
+	// Free is left to the broker's default, free, when nil.
+	Free *bool `json:"free,omitempty"`
+}
+
+// AsyncOutcome is how the async_service_broker asset answers the polls for
+// an operation once it has reported progress. State defaults to "succeeded";
+// "in progress" never ends. A Status other than 200, e.g. 410 Gone, is sent
+// without a body.
+type AsyncOutcome struct {
+	State       string `json:"state,omitempty"`
+	Description string `json:"description,omitempty"`
+	Status      int    `json:"status,omitempty"`
 }
 
 type ServiceBroker struct {
@@ -32,46 +49,26 @@ type ServiceBroker struct {
 		}
 	}
 	Plans []Plan
-}
-
-type ServicesResponse struct {
-	Resources []ServiceResponse
-}
 
-type ServiceResponse struct {
-	Entity struct {
-		Label        string
-		ServicePlans []ServicePlanResponse `json:"service_plans"`
-	}
+	// LastOperations sets the AsyncOutcome of provision, update or
+	// deprovision operations of the async broker. Deprovisioning is
+	// synchronous unless an outcome is set for it.
+	LastOperations map[fakebroker.Operation]AsyncOutcome
 }
 
-type ServicePlanResponse struct {
-	Entity struct {
-		Name   string
-		Public bool
-	}
-	Metadata struct {
-		Url  string
-		Guid string
-	}
-}
+// brokerNamePrefix marks brokers as created by CATS, so that cats-janitor can
+// find the ones an aborted run left registered.
+const brokerNamePrefix = "CATS-BROKER-"
 
-type ServiceInstance struct {
-	Metadata struct {
-		Guid string `json:"guid"`
-	}
-}
-
-type ServiceInstanceResponse struct {
-	Resources []ServiceInstance
-}
+// The credentials brokers are registered with, which the Cloud Controller
+// sends them on every request.
+const (
+	brokerUsername = "username"
+	brokerPassword = "password"
+)
 
-type SpaceJson struct {
-	Resources []struct {
-		Metadata struct {
-			Guid string
-		}
-	}
+func randomBrokerName() string {
+	return brokerNamePrefix + generator.RandomName()
 }
 
 func NewServiceBroker(name string, path string, context helpers.SuiteContext) ServiceBroker {
@@ -89,6 +86,7 @@ func NewServiceBroker(name string, path string, context helpers.SuiteContext) Se
 }
 
 func (b ServiceBroker) Push() {
+	cleanup.Current().App(b.Name)
 	Expect(cf.Cf("push", b.Name, "-p", b.Path).Wait(BROKER_START_TIMEOUT)).To(Exit(0))
 }
 
@@ -103,14 +101,19 @@ func (b ServiceBroker) Restart() {
 
 func (b ServiceBroker) Create() {
 	cf.AsUser(b.context.AdminUserContext(), func() {
-		Expect(cf.Cf("create-service-broker", b.Name, "username", "password", helpers.AppUri(b.Name, "")).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+		Expect(cf.Cf("create-service-broker", b.Name, brokerUsername, brokerPassword, helpers.AppUri(b.Name, "")).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+		cleanup.Current().ServiceBroker(b.Name)
+		cleanup.Current().ServiceOffering(b.Service.Name)
 		Expect(cf.Cf("service-brokers").Wait(DEFAULT_TIMEOUT)).To(Say(b.Name))
 	})
 }
 
+// Update makes the Cloud Controller re-read the catalog, which may now offer
+// the service under a new name.
 func (b ServiceBroker) Update() {
 	cf.AsUser(b.context.AdminUserContext(), func() {
-		Expect(cf.Cf("update-service-broker", b.Name, "username", "password", helpers.AppUri(b.Name, "")).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+		Expect(cf.Cf("update-service-broker", b.Name, brokerUsername, brokerPassword, helpers.AppUri(b.Name, "")).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+		cleanup.Current().ServiceOffering(b.Service.Name)
 	})
 }
 
@@ -124,40 +127,27 @@ func (b ServiceBroker) Delete() {
 	})
 }
 
-func (b ServiceBroker) Destroy() {
-	cf.AsUser(b.context.AdminUserContext(), func() {
-		Expect(cf.Cf("purge-service-offering", b.Service.Name, "-f").Wait(DEFAULT_TIMEOUT)).To(Exit(0))
-	})
-	b.Delete()
-	Expect(cf.Cf("delete", b.Name, "-f").Wait(DEFAULT_TIMEOUT)).To(Exit(0))
-}
-
 func (b ServiceBroker) ToJSON() string {
 	attributes := make(map[string]interface{})
 	attributes["service"] = b.Service
 	attributes["plans"] = b.Plans
 	attributes["dashboard_client"] = b.Service.DashboardClient
+	if len(b.LastOperations) > 0 {
+		attributes["last_operations"] = b.LastOperations
+	}
 	jsonBytes, _ := json.Marshal(attributes)
 	return string(jsonBytes)
 }
 
 func (b ServiceBroker) PublicizePlans() {
-	url := fmt.Sprintf("/v2/services?inline-relations-depth=1&q=label:%s", b.Service.Name)
-	var session *Session
+	var service ccapi.Service
 	cf.AsUser(b.context.AdminUserContext(), func() {
-		session = cf.Cf("curl", url).Wait(DEFAULT_TIMEOUT)
-		Expect(session).To(Exit(0))
+		service = ccapi.FindService(b.Service.Name)
 	})
-	structure := ServicesResponse{}
-	json.Unmarshal(session.Out.Contents(), &structure)
-
-	for _, service := range structure.Resources {
-		if service.Entity.Label == b.Service.Name {
-			for _, plan := range service.Entity.ServicePlans {
-				if b.HasPlan(plan.Entity.Name) {
-					b.PublicizePlan(plan.Metadata.Url)
-				}
-			}
+
+	for _, plan := range service.Entity.ServicePlans {
+		if b.HasPlan(plan.Entity.Name) {
+			b.PublicizePlan(plan.Metadata.Url)
 		}
 	}
 }
@@ -172,29 +162,88 @@ func (b ServiceBroker) HasPlan(planName string) bool {
 }
 
 func (b ServiceBroker) PublicizePlan(url string) {
-	jsonMap := make(map[string]bool)
-	jsonMap["public"] = true
-	planJson, _ := json.Marshal(jsonMap)
 	cf.AsUser(b.context.AdminUserContext(), func() {
-		Expect(cf.Cf("curl", url, "-X", "PUT", "-d", string(planJson)).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+		ccapi.Put(url, nil, `{"public":true}`)
 	})
 }
 
 func (b ServiceBroker) CreateServiceInstance(instanceName string) string {
-	Expect(cf.Cf("create-service", b.Service.Name, b.Plans[0].Name, instanceName).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
-	url := fmt.Sprintf("/v2/service_instances?q=name:%s", instanceName)
-	serviceInstance := ServiceInstanceResponse{}
-	curl := cf.Cf("curl", url).Wait(DEFAULT_TIMEOUT)
-	Expect(curl).To(Exit(0))
-	json.Unmarshal(curl.Out.Contents(), &serviceInstance)
-	return serviceInstance.Resources[0].Metadata.Guid
-}
-
-func (b ServiceBroker) GetSpaceGuid() string {
-	url := fmt.Sprintf("/v2/spaces?q=name%%3A%s", b.context.RegularUserContext().Space)
-	jsonResults := SpaceJson{}
-	curl := cf.Cf("curl", url).Wait(DEFAULT_TIMEOUT)
-	Expect(curl).To(Exit(0))
-	json.Unmarshal(curl.Out.Contents(), &jsonResults)
-	return jsonResults.Resources[0].Metadata.Guid
+	return b.createServiceInstance(instanceName)
+}
+
+// CreateServiceInstanceWithParameters creates an instance of the first plan
+// configured with parameters: inline JSON or the path of a JSON file, as
+// `cf create-service -c` takes them.
+func (b ServiceBroker) CreateServiceInstanceWithParameters(instanceName, parameters string) string {
+	return b.createServiceInstance(instanceName, "-c", parameters)
+}
+
+func (b ServiceBroker) createServiceInstance(instanceName string, flags ...string) string {
+	cleanup.Current().ServiceInstance(instanceName)
+	args := append([]string{"create-service", b.Service.Name, b.Plans[0].Name, instanceName}, flags...)
+	ExpectWithOffset(2, cf.Cf(args...).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+	return ccapi.FindServiceInstance(instanceName).Metadata.Guid
+}
+
+// UpdateServiceInstance reconfigures the instance with parameters, given as
+// for CreateServiceInstanceWithParameters.
+func (b ServiceBroker) UpdateServiceInstance(instanceName, parameters string) {
+	ExpectWithOffset(1, cf.Cf("update-service", instanceName, "-c", parameters).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+}
+
+// BindServiceInstance binds the instance to the app with parameters, given as
+// for CreateServiceInstanceWithParameters.
+func (b ServiceBroker) BindServiceInstance(appName, instanceName, parameters string) {
+	ExpectWithOffset(1, cf.Cf("bind-service", appName, instanceName, "-c", parameters).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+}
+
+// writeParametersFile writes parameters to a JSON file for -c, which is
+// removed after the spec.
+func writeParametersFile(parameters string) string {
+	file, err := ioutil.TempFile("", "parameters")
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	defer file.Close()
+
+	cleanup.Current().Track("parameters file "+file.Name(), func() error {
+		return os.Remove(file.Name())
+	})
+
+	_, err = file.WriteString(parameters)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	return file.Name()
+}
+
+// waitForLastOperation waits ASYNC_SERVICE_OPERATION_TIMEOUT for the last
+// operation of the service instance to satisfy done, e.g.
+// ccapi.LastOperation.Finished, and returns it.
+func waitForLastOperation(instanceGuid string, done func(ccapi.LastOperation) bool) ccapi.LastOperation {
+	operation, err := ccapi.WaitForLastOperation(instanceGuid, ASYNC_SERVICE_OPERATION_TIMEOUT, done)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	return operation
+}
+
+// Journal returns the requests the broker app received on the v2 API, oldest
+// first.
+func (b ServiceBroker) Journal() []fakebroker.Request {
+	return fakebroker.NewRemote(helpers.AppUri(b.Name, ""), helpers.LoadConfig().SkipSSLValidation).Journal()
+}
+
+// Requests returns the journaled requests for the operation on the service
+// instance, oldest first.
+func (b ServiceBroker) Requests(operation fakebroker.Operation, instanceGuid string) []fakebroker.Request {
+	requests := []fakebroker.Request{}
+	for _, request := range b.Journal() {
+		if request.Operation == operation && request.InstanceID == instanceGuid {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// LastRequest returns the latest request for the operation on the service
+// instance, failing the spec if the broker received none.
+func (b ServiceBroker) LastRequest(operation fakebroker.Operation, instanceGuid string) fakebroker.Request {
+	requests := b.Requests(operation, instanceGuid)
+	ExpectWithOffset(1, requests).NotTo(BeEmpty(), "the broker received no %s request for %s", operation, instanceGuid)
+	return requests[len(requests)-1]
 }
@@ -8,20 +8,28 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+	catsconfig "github.com/cloudfoundry/cf-acceptance-tests/helpers/config"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/suite"
 )
 
 var (
 	DEFAULT_TIMEOUT      = 30 * time.Second
 	CF_PUSH_TIMEOUT      = 2 * time.Minute
 	BROKER_START_TIMEOUT = 5 * time.Minute
+
+	ASYNC_SERVICE_OPERATION_TIMEOUT = 5 * time.Minute
+	BROKER_MAX_ASYNC_POLL_DURATION  = 10 * time.Minute
 )
 
-var context helpers.SuiteContext
+var context suite.SuiteContext
 
 func TestApplications(t *testing.T) {
 	RegisterFailHandler(Fail)
 
-	config := helpers.LoadConfig()
+	config := catsconfig.Load("services")
+	defer config.RemoveEffectiveConfig()
+	config.SkipSuiteUnless(t, "include_services")
 
 	if config.DefaultTimeout > 0 {
 		DEFAULT_TIMEOUT = config.DefaultTimeout * time.Second
@@ -31,12 +39,21 @@ func TestApplications(t *testing.T) {
 		CF_PUSH_TIMEOUT = config.CfPushTimeout * time.Second
 	}
 
-	if config.CfPushTimeout > 0 {
+	if config.BrokerStartTimeout > 0 {
 		BROKER_START_TIMEOUT = config.BrokerStartTimeout * time.Second
 	}
 
-	context = helpers.NewContext(config)
+	if config.AsyncServiceOperationTimeout > 0 {
+		ASYNC_SERVICE_OPERATION_TIMEOUT = config.AsyncServiceOperationTimeout * time.Second
+	}
+
+	if config.BrokerMaxAsyncPollDuration > 0 {
+		BROKER_MAX_ASYNC_POLL_DURATION = config.BrokerMaxAsyncPollDuration * time.Second
+	}
+
+	context = suite.NewContext(config.Config)
 	environment := helpers.NewEnvironment(context)
+	cleanup.Install(context.AdminUserContext())
 
 	BeforeSuite(func() {
 		environment.Setup()
@@ -51,9 +68,11 @@ func TestApplications(t *testing.T) {
 	rs := []Reporter{}
 
 	if config.ArtifactsDirectory != "" {
-		helpers.EnableCFTrace(config, componentName)
-		rs = append(rs, helpers.NewJUnitReporter(config, componentName))
+		helpers.EnableCFTrace(config.Config, componentName)
+		rs = append(rs, helpers.NewJUnitReporter(config.Config, componentName))
 	}
 
+	config.SkipExcludedSpecs()
+
 	RunSpecsWithDefaultAndCustomReporters(t, componentName, rs)
 }
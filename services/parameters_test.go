@@ -0,0 +1,83 @@
+package services
+
+import (
+	"encoding/json"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakebroker"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Service parameters", func() {
+	const parameters = `{
+		"size": "large",
+		"nested": {"list": [1, "two", {"three": true}], "empty": {}, "nothing": null},
+		"unicode": "héllo ☃ 日本語"
+	}`
+
+	var broker ServiceBroker
+	var expected map[string]interface{}
+
+	provisionParameters := func(operation fakebroker.Operation, instanceGuid string) map[string]interface{} {
+		var details fakebroker.ProvisionDetails
+		ExpectWithOffset(1, broker.LastRequest(operation, instanceGuid).DecodeBody(&details)).To(Succeed())
+		return details.Parameters
+	}
+
+	BeforeEach(func() {
+		context.IsolateSpace()
+
+		expected = map[string]interface{}{}
+		Expect(json.Unmarshal([]byte(parameters), &expected)).To(Succeed())
+
+		broker = NewServiceBroker(randomBrokerName(), assets.NewAssets().ServiceBroker, context)
+		broker.Push()
+		broker.Configure()
+		broker.Create()
+		broker.PublicizePlans()
+	})
+
+	It("passes inline JSON to the broker on create", func() {
+		instanceGuid := broker.CreateServiceInstanceWithParameters(generator.RandomName(), parameters)
+		Expect(provisionParameters(fakebroker.Provision, instanceGuid)).To(Equal(expected))
+	})
+
+	It("passes a JSON file to the broker on create", func() {
+		instanceGuid := broker.CreateServiceInstanceWithParameters(generator.RandomName(), writeParametersFile(parameters))
+		Expect(provisionParameters(fakebroker.Provision, instanceGuid)).To(Equal(expected))
+	})
+
+	It("passes parameters to the broker on update", func() {
+		instanceName := generator.RandomName()
+		instanceGuid := broker.CreateServiceInstance(instanceName)
+
+		broker.UpdateServiceInstance(instanceName, parameters)
+		Expect(provisionParameters(fakebroker.Update, instanceGuid)).To(Equal(expected))
+	})
+
+	It("passes parameters to the broker on bind and stores the credentials it echoes them in", func() {
+		instanceName := generator.RandomName()
+		instanceGuid := broker.CreateServiceInstance(instanceName)
+
+		appName := generator.RandomName()
+		cleanup.Current().App(appName)
+		Expect(cf.Cf("push", appName, "-p", assets.NewAssets().Dora, "--no-start").Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
+		appGuid := ccapi.FindApp(appName).Metadata.Guid
+
+		broker.BindServiceInstance(appName, instanceName, writeParametersFile(parameters))
+
+		var details fakebroker.BindDetails
+		Expect(broker.LastRequest(fakebroker.Bind, instanceGuid).DecodeBody(&details)).To(Succeed())
+		Expect(details.Parameters).To(Equal(expected))
+
+		binding := ccapi.FindServiceBinding(appGuid, instanceGuid)
+		Expect(binding.Entity.Credentials).To(HaveKeyWithValue("parameters", expected))
+	})
+})
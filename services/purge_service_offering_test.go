@@ -7,7 +7,6 @@ import (
 	. "github.com/onsi/gomega/gexec"
 
 	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
-	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
 )
 
@@ -15,17 +14,13 @@ var _ = Describe("Purging service offerings", func() {
 	var broker ServiceBroker
 
 	BeforeEach(func() {
-		broker = NewServiceBroker(generator.RandomName(), assets.NewAssets().ServiceBroker, context)
+		broker = NewServiceBroker(randomBrokerName(), assets.NewAssets().ServiceBroker, context)
 		broker.Push()
 		broker.Configure()
 		broker.Create()
 		broker.PublicizePlans()
 	})
 
-	AfterEach(func() {
-		broker.Destroy()
-	})
-
 	It("removes all instances and plans of the service, then removes the service offering", func() {
 		instanceName := "purge-offering-instance"
 
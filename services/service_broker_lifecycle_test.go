@@ -14,7 +14,7 @@ var _ = Describe("Service Broker Lifecycle", func() {
 	var broker ServiceBroker
 
 	BeforeEach(func() {
-		broker = NewServiceBroker(generator.RandomName(), assets.NewAssets().ServiceBroker, context)
+		broker = NewServiceBroker(randomBrokerName(), assets.NewAssets().ServiceBroker, context)
 	})
 
 	serviceBrokerTest := func(broker ServiceBroker) {
@@ -66,8 +66,6 @@ var _ = Describe("Service Broker Lifecycle", func() {
 		Expect(output).NotTo(ContainSubstring(oldPlanName))
 		Expect(output).NotTo(ContainSubstring(broker.Service.Name))
 		Expect(output).NotTo(ContainSubstring(broker.Plans[0].Name))
-
-		broker.Destroy()
 	}
 
 	It("confirms correct behavior in the lifecycle of a service broker", func() {
@@ -1,66 +1,43 @@
 package services
 
 import (
-	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
 	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/fakebroker"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gexec"
 )
 
-type LastOperation struct {
-	State string `json:"state"`
-}
-
-type Service struct {
-	Name          string        `json:"name"`
-	LastOperation LastOperation `json:"last_operation"`
-}
-
-type Resource struct {
-	Entity Service `json:"entity"`
-}
-
-type Response struct {
-	Resources []Resource `json:"resources"`
-}
-
 var _ = Describe("Service Instance Lifecycle", func() {
 	var broker ServiceBroker
 
-	waitForAsyncOperationToComplete := func(broker ServiceBroker, instanceName string) {
-		// TODO: Use this code when CLI supports async
-		// Eventually(func() string {
-		// 	serviceDetails := cf.Cf("service", instanceName).Wait(DEFAULT_TIMEOUT)
-		// 	Expect(createService).To(Exit(0), "failed getting service instance details")
-		// 	return string(serviceDetails.Out.Contents())
-		// }, 5*time.Minute, 15*time.Second).Should(ContainSubstring("succeeded"))
-
-		Eventually(func() string {
-			serviceDetails := cf.Cf("curl", fmt.Sprintf("/v2/spaces/%s/service_instances", broker.GetSpaceGuid())).Wait(DEFAULT_TIMEOUT)
-			Expect(serviceDetails).To(Exit(0), "failed getting service instance details")
-
-			var response Response
-			Expect(json.Unmarshal(serviceDetails.Out.Contents(), &response)).ToNot(HaveOccurred())
-
-			for _, resource := range response.Resources {
-				if resource.Entity.Name == instanceName {
-					return resource.Entity.LastOperation.State
-				}
-			}
-			return ""
-		}, 5*time.Minute, 15*time.Second).Should(Equal("succeeded"))
+	waitForAsyncOperationToComplete := func(instanceName string) {
+		operation := waitForLastOperation(ccapi.FindServiceInstance(instanceName).Metadata.Guid, ccapi.LastOperation.Finished)
+		ExpectWithOffset(1, operation.State).To(Equal("succeeded"), operation.String())
+	}
+
+	expectSentByCloudController := func(request fakebroker.Request) {
+		username, password, ok := request.Credentials()
+		ExpectWithOffset(1, ok).To(BeTrue(), "%s request without basic auth", request.Operation)
+		ExpectWithOffset(1, username).To(Equal(brokerUsername))
+		ExpectWithOffset(1, password).To(Equal(brokerPassword))
+		ExpectWithOffset(1, request.Header.Get("X-Broker-Api-Version")).To(MatchRegexp(`^2\.\d+$`))
 	}
 
+	BeforeEach(func() {
+		context.IsolateSpace()
+	})
+
 	Context("Sync broker", func() {
 		BeforeEach(func() {
-			broker = NewServiceBroker(generator.RandomName(), assets.NewAssets().ServiceBroker, context)
+			broker = NewServiceBroker(randomBrokerName(), assets.NewAssets().ServiceBroker, context)
 			broker.Plans = append(broker.Plans, Plan{Name: generator.RandomName(), ID: generator.RandomName()})
 			broker.Push()
 			broker.Configure()
@@ -68,10 +45,6 @@ var _ = Describe("Service Instance Lifecycle", func() {
 			broker.PublicizePlans()
 		})
 
-		AfterEach(func() {
-			broker.Destroy()
-		})
-
 		Context("just service instances", func() {
 			It("can create, update, and delete a service instance", func() {
 				instanceName := generator.RandomName()
@@ -95,6 +68,60 @@ var _ = Describe("Service Instance Lifecycle", func() {
 			})
 		})
 
+		Context("what the broker receives", func() {
+			var instanceName string
+			var instanceGuid string
+
+			BeforeEach(func() {
+				instanceName = generator.RandomName()
+				instanceGuid = broker.CreateServiceInstance(instanceName)
+			})
+
+			It("sends the plan, org and space of a new service instance", func() {
+				space := ccapi.FindSpace(context.RegularUserContext().Space)
+
+				provision := broker.LastRequest(fakebroker.Provision, instanceGuid)
+				expectSentByCloudController(provision)
+
+				var details fakebroker.ProvisionDetails
+				Expect(provision.DecodeBody(&details)).To(Succeed())
+				Expect(details).To(Equal(fakebroker.ProvisionDetails{
+					ServiceID:        broker.Service.ID,
+					PlanID:           broker.Plans[0].ID,
+					OrganizationGUID: space.Entity.OrganizationGuid,
+					SpaceGUID:        space.Metadata.Guid,
+				}))
+			})
+
+			It("sends the app a service instance is bound to and unbound from", func() {
+				appName := generator.RandomName()
+				cleanup.Current().App(appName)
+				Expect(cf.Cf("push", appName, "-p", assets.NewAssets().Dora, "--no-start").Wait(CF_PUSH_TIMEOUT)).To(Exit(0))
+				appGuid := ccapi.FindApp(appName).Metadata.Guid
+
+				Expect(cf.Cf("bind-service", appName, instanceName).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+
+				bind := broker.LastRequest(fakebroker.Bind, instanceGuid)
+				expectSentByCloudController(bind)
+
+				var details fakebroker.BindDetails
+				Expect(bind.DecodeBody(&details)).To(Succeed())
+				Expect(details).To(Equal(fakebroker.BindDetails{
+					ServiceID: broker.Service.ID,
+					PlanID:    broker.Plans[0].ID,
+					AppGUID:   appGuid,
+				}))
+
+				Expect(cf.Cf("unbind-service", appName, instanceName).Wait(DEFAULT_TIMEOUT)).To(Exit(0))
+
+				unbind := broker.LastRequest(fakebroker.Unbind, instanceGuid)
+				expectSentByCloudController(unbind)
+				Expect(unbind.BindingID).To(Equal(bind.BindingID))
+				Expect(unbind.QueryParam("service_id")).To(Equal(broker.Service.ID))
+				Expect(unbind.QueryParam("plan_id")).To(Equal(broker.Plans[0].ID))
+			})
+		})
+
 		Context("service instances with an app", func() {
 			It("can bind and unbind service to app and check app env and events", func() {
 				appName := generator.RandomName()
@@ -141,7 +168,7 @@ var _ = Describe("Service Instance Lifecycle", func() {
 
 	Context("Async broker", func() {
 		BeforeEach(func() {
-			broker = NewServiceBroker(generator.RandomName(), assets.NewAssets().AsyncServiceBroker, context)
+			broker = NewServiceBroker(randomBrokerName(), assets.NewAssets().AsyncServiceBroker, context)
 			broker.Plans = append(broker.Plans, Plan{Name: generator.RandomName(), ID: generator.RandomName()})
 			broker.Push()
 			broker.Configure()
@@ -149,10 +176,6 @@ var _ = Describe("Service Instance Lifecycle", func() {
 			broker.PublicizePlans()
 		})
 
-		AfterEach(func() {
-			broker.Destroy()
-		})
-
 		Context("just service instances", func() {
 			It("can create, update, bind, unbind, and delete a service instance", func() {
 				appName := generator.RandomName()
@@ -165,7 +188,7 @@ var _ = Describe("Service Instance Lifecycle", func() {
 				createService := cf.Cf("create-service", broker.Service.Name, broker.Plans[0].Name, instanceName).Wait(DEFAULT_TIMEOUT)
 				Expect(createService).To(Exit(0))
 
-				waitForAsyncOperationToComplete(broker, instanceName)
+				waitForAsyncOperationToComplete(instanceName)
 
 				serviceInfo := cf.Cf("service", instanceName).Wait(DEFAULT_TIMEOUT)
 				Expect(serviceInfo.Out.Contents()).To(ContainSubstring(fmt.Sprintf("Plan: %s", broker.Plans[0].Name)))
@@ -176,7 +199,7 @@ var _ = Describe("Service Instance Lifecycle", func() {
 				updateService := cf.Cf("update-service", instanceName, "-p", broker.Plans[1].Name).Wait(DEFAULT_TIMEOUT)
 				Expect(updateService).To(Exit(0))
 
-				waitForAsyncOperationToComplete(broker, instanceName)
+				waitForAsyncOperationToComplete(instanceName)
 
 				serviceInfo = cf.Cf("service", instanceName).Wait(DEFAULT_TIMEOUT)
 				Expect(serviceInfo).To(Exit(0), "failed getting service instance details")
@@ -216,10 +239,12 @@ var _ = Describe("Service Instance Lifecycle", func() {
 })
 
 func checkForEvents(name string, eventNames []string) {
-	events := cf.Cf("events", name).Wait(DEFAULT_TIMEOUT)
-	Expect(events).To(Exit(0), fmt.Sprintf("failed getting events for %s", name))
+	eventTypes := []string{}
+	for _, event := range ccapi.AppEvents(ccapi.FindApp(name).Metadata.Guid) {
+		eventTypes = append(eventTypes, event.Entity.Type)
+	}
 
 	for _, eventName := range eventNames {
-		Expect(events.Out.Contents()).To(ContainSubstring(eventName), "failed to find event")
+		Expect(eventTypes).To(ContainElement(eventName), fmt.Sprintf("failed to find event %s for %s", eventName, name))
 	}
 }
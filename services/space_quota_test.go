@@ -0,0 +1,70 @@
+package services
+
+import (
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/cleanup"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/suite"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Space quotas", func() {
+	var (
+		broker   ServiceBroker
+		paidPlan Plan
+		quota    suite.Quota
+	)
+
+	createService := func(plan Plan) *Session {
+		instanceName := generator.RandomName()
+		cleanup.Current().ServiceInstance(instanceName)
+		return cf.Cf("create-service", broker.Service.Name, plan.Name, instanceName).Wait(DEFAULT_TIMEOUT)
+	}
+
+	BeforeEach(func() {
+		context.IsolateSpace()
+
+		free := false
+		paidPlan = Plan{Name: generator.RandomName(), ID: generator.RandomName(), Free: &free}
+
+		broker = NewServiceBroker(randomBrokerName(), assets.NewAssets().ServiceBroker, context)
+		broker.Plans = append(broker.Plans, paidPlan)
+		broker.Push()
+		broker.Configure()
+		broker.Create()
+		broker.PublicizePlans()
+
+		quota = suite.DefaultQuota()
+	})
+
+	It("stops service instances from being created beyond the space's total", func() {
+		quota.ServiceInstances = 0
+		context.ApplySpaceQuota(quota)
+
+		createInstance := createService(broker.Plans[0])
+		Expect(createInstance).To(Exit(1))
+		Expect(createInstance).To(Say("services limit"))
+	})
+
+	Context("when paid plans are not allowed", func() {
+		BeforeEach(func() {
+			quota.NonBasicServicesAllowed = false
+			context.ApplySpaceQuota(quota)
+		})
+
+		It("stops instances of paid plans from being created", func() {
+			createInstance := createService(paidPlan)
+			Expect(createInstance).To(Exit(1))
+			Expect(createInstance).To(Say("paid service plans are not allowed"))
+		})
+
+		It("still creates instances of free plans", func() {
+			Expect(createService(broker.Plans[0])).To(Exit(0))
+		})
+	})
+})
@@ -7,17 +7,20 @@ import (
 	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
 	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	catsconfig "github.com/cloudfoundry/cf-acceptance-tests/helpers/config"
 )
 
-var _ = Describe("SSO Lifecycle", func() {
+var _ = Describe("SSO Lifecycle "+catsconfig.Tag("include_sso"), func() {
 	var broker ServiceBroker
 	var config OAuthConfig
-	var apiEndpoint = helpers.LoadConfig().ApiEndpoint
+	var apiEndpoint string
 
 	redirectUri := `http://example.com`
 
 	BeforeEach(func() {
-		broker = NewServiceBroker(generator.RandomName(), assets.NewAssets().ServiceBroker, context)
+		apiEndpoint = helpers.LoadConfig().ApiEndpoint
+
+		broker = NewServiceBroker(randomBrokerName(), assets.NewAssets().ServiceBroker, context)
 		broker.Push()
 		broker.Service.DashboardClient.RedirectUri = redirectUri
 		broker.Configure()
@@ -31,10 +34,6 @@ var _ = Describe("SSO Lifecycle", func() {
 		SetOauthEndpoints(apiEndpoint, &config)
 	})
 
-	AfterEach(func() {
-		broker.Destroy()
-	})
-
 	Context("When a service broker is created", func() {
 		It("can perform an operation on a user's behalf using sso", func() {
 			broker.Create()
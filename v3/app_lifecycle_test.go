@@ -0,0 +1,60 @@
+package v3
+
+import (
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/ccapi"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/matchers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("v3 app lifecycle", func() {
+	var appName string
+	var appGuid string
+	var routeGuid string
+
+	BeforeEach(func() {
+		appName = generator.RandomName()
+		spaceGuid := ccapi.FindSpace(context.RegularUserContext().Space).Metadata.Guid
+
+		appGuid = ccapi.CreateV3App(appName, spaceGuid).Guid
+		packageGuid := ccapi.CreatePackage(appGuid, "bits").Guid
+		ccapi.UploadPackage(packageGuid, assets.NewAssets().Dora, CF_PUSH_TIMEOUT)
+
+		droplet := ccapi.StagePackage(packageGuid, map[string]interface{}{}, CF_PUSH_TIMEOUT)
+		ccapi.AssignDroplet(appGuid, droplet.Guid)
+
+		domainGuid := ccapi.FindSharedDomain(config.AppsDomain).Metadata.Guid
+		routeGuid = ccapi.CreateRoute(appName, domainGuid, spaceGuid).Metadata.Guid
+		ccapi.MapV3Route(appGuid, routeGuid)
+
+		ccapi.StartV3App(appGuid)
+	})
+
+	It("serves traffic from the assigned droplet", func() {
+		Expect(ccapi.GetV3App(appGuid).DesiredState).To(Equal("STARTED"))
+
+		Eventually(func() string {
+			return helpers.CurlAppRoot(appName)
+		}, CF_PUSH_TIMEOUT).Should(ContainSubstring("Hi, I'm Dora!"))
+	})
+
+	Describe("stopping", func() {
+		BeforeEach(func() {
+			Eventually(func() string {
+				return helpers.CurlAppRoot(appName)
+			}, CF_PUSH_TIMEOUT).Should(ContainSubstring("Hi, I'm Dora!"))
+
+			ccapi.StopV3App(appGuid)
+		})
+
+		It("makes the app unreachable", func() {
+			Expect(ccapi.GetV3App(appGuid).DesiredState).To(Equal("STOPPED"))
+
+			Eventually(appClient.Probing(appName, "/"), DEFAULT_TIMEOUT).Should(BeUnroutable())
+		})
+	})
+})